@@ -2,15 +2,13 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"runtime/debug"
 	"strings"
 	"time"
 
-	"github.com/pkg/errors"
-
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -29,35 +27,95 @@ func (r *statusCodeRecorder) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap provides the underlying writer, allowing its optional interfaces, such as flushing, to be reached.
+func (r *statusCodeRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // API represents the context for the public interface.
 type API struct {
 	App    *app.App
 	Config *Config
+	// Authenticator identifies the clients making requests, or is nil when every route is open
+	Authenticator Authenticator
+
+	// limiter holds the token buckets of clients, or is nil when requests are never limited
+	limiter *rateLimiter
+	// trustedProxies holds the ranges of addresses of the proxies trusted to report the addresses of clients
+	trustedProxies []netip.Prefix
 }
 
 // New creates a new API instance.
-func New(a *app.App) (api *API) {
-	api = &API{App: a}
+func New(a *app.App) (api *API, err error) {
+	api = &API{App: a, limiter: newRateLimiter()}
 	api.Config = initConfig()
-	return api
+	if err = api.Config.validate(); err != nil {
+		return nil, err
+	}
+	if api.trustedProxies, err = parseTrustedProxies(api.Config.TrustedProxies); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(api.Config.AuthMethod, authMethodNone) {
+		if api.Authenticator, err = newAuthenticator(api.Config); err != nil {
+			return nil, err
+		}
+	}
+	return api, nil
 }
 
 // Init is where we define the routes our API will support.
 func (a *API) Init(r *mux.Router) {
+	r.NotFoundHandler = a.handler(func(*app.Context, http.ResponseWriter, *http.Request) error {
+		return &app.UserError{StatusCode: http.StatusNotFound, Message: "not found"}
+	})
+	r.MethodNotAllowedHandler = a.handler(func(*app.Context, http.ResponseWriter, *http.Request) error {
+		return &app.UserError{StatusCode: http.StatusMethodNotAllowed, Message: "method not allowed"}
+	})
 	r.Handle("/hello", a.handler(a.helloHandler))
 
 	// place methods
+	r.Handle("/places:batch", a.handler(a.idempotent(a.batchPlaces))).Methods("POST").Name("batch_places")
 	placesRouter := r.PathPrefix("/places").Subrouter()
-	placesRouter.Handle("", a.handler(a.getPlaces)).Methods("GET")
-	placesRouter.Handle("", a.handler(a.createPlace)).Methods("POST")
-	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.getPlaceByID)).Methods("GET")
-	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.updatePlaceByID)).Methods("PATCH")
-	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.deletePlaceByID)).Methods("DELETE")
+	placesRouter.Handle("", a.handler(a.getPlaces)).Methods("GET").Name("get_places")
+	placesRouter.Handle("", a.handler(a.idempotent(a.createPlace))).Methods("POST").Name("create_place")
+	placesRouter.Handle("/nearest", a.handler(a.getNearestPlaces)).Methods("GET").Name("get_nearest_places")
+	placesRouter.Handle("/facets", a.handler(a.getPlaceFacets)).Methods("GET").Name("get_place_facets")
+	placesRouter.Handle("/suggest", a.handler(a.suggestPlaces)).Methods("GET").Name("suggest_places")
+	placesRouter.Handle("/trash", a.handler(a.getDeletedPlaces)).Methods("GET").Name("get_deleted_places")
+	placesRouter.Handle("/export", a.handler(a.exportPlaces)).Methods("GET").Name("export_places")
+	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.getPlaceByID)).Methods("GET").Name("get_place")
+	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.idempotent(a.updatePlaceByID))).Methods("PATCH").Name("update_place")
+	placesRouter.Handle("/{id:[0-9]+}", a.handler(a.idempotent(a.deletePlaceByID))).Methods("DELETE").Name("delete_place")
+	placesRouter.Handle("/{id:[0-9]+}/restore", a.handler(a.idempotent(a.restorePlaceByID))).
+		Methods("POST").Name("restore_place")
+	placesRouter.Handle("/{id:[0-9]+}/history", a.handler(a.getPlaceHistory)).Methods("GET").Name("get_place_history")
+	placesRouter.Handle("/{id:[0-9]+}/revert", a.handler(a.idempotent(a.revertPlaceByID))).
+		Methods("POST").Name("revert_place")
+
+	// tag methods
+	tagsRouter := r.PathPrefix("/tags").Subrouter()
+	tagsRouter.Handle("", a.handler(a.getTags)).Methods("GET").Name("get_tags")
+	tagsRouter.Handle("", a.handler(a.idempotent(a.createTag))).Methods("POST").Name("create_tag")
+	tagsRouter.Handle("/{id:[0-9]+}", a.handler(a.getTagByID)).Methods("GET").Name("get_tag")
+	tagsRouter.Handle("/{id:[0-9]+}", a.handler(a.idempotent(a.updateTagByID))).Methods("PATCH").Name("update_tag")
+	tagsRouter.Handle("/{id:[0-9]+}", a.handler(a.idempotent(a.deleteTagByID))).Methods("DELETE").Name("delete_tag")
+
+	// tenant methods
+	tenantsRouter := r.PathPrefix("/tenants").Subrouter()
+	tenantsRouter.Handle("", a.handler(a.getTenants)).Methods("GET").Name("get_tenants")
+	tenantsRouter.Handle("", a.handler(a.idempotent(a.createTenant))).Methods("POST").Name("create_tenant")
 }
 
-func (a *API) handler(f func(*app.Context, http.ResponseWriter, *http.Request) error) http.Handler {
+// handlerFunc serves a request within the request-scoped context, returning any error to be reported to the client.
+type handlerFunc func(*app.Context, http.ResponseWriter, *http.Request) error
+
+func (a *API) handler(f handlerFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, 100*1024*1024)
+		var routeName string
+		if route := mux.CurrentRoute(r); route != nil {
+			routeName = route.GetName()
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, a.Config.maxBodyBytes(routeName))
 
 		beginTime := time.Now()
 		traceID, _ := uuid.NewUUID()
@@ -78,64 +136,69 @@ func (a *API) handler(f func(*app.Context, http.ResponseWriter, *http.Request) e
 			}
 			duration := time.Since(beginTime)
 
-			ctx.Logger.WithFields(logrus.Fields{
+			fields := logrus.Fields{
 				"duration":       duration,
 				"status_code":    statusCode,
 				"remote_address": ctx.RemoteAddress,
 				"trace_id":       ctx.TraceID,
-			}).Info(r.Method + " " + r.URL.RequestURI())
+			}
+			if ctx.Principal != nil {
+				fields["principal"] = ctx.Principal.ID
+			}
+			if ctx.Tenant != "" {
+				fields["tenant"] = ctx.Tenant
+			}
+			ctx.Logger.WithFields(fields).Info(r.Method + " " + r.URL.RequestURI())
 		}()
 
 		defer func() {
-			if r := recover(); r != nil {
-				ctx.Logger.Error(fmt.Errorf("%v: %s", r, debug.Stack()))
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					// The handler has abandoned a response already begun, so the server must abort the connection
+					panic(recovered)
+				}
+				ctx.Logger.Error(fmt.Errorf("%v: %s", recovered, debug.Stack()))
+				writeProblem(ctx, w, r, newProblem(http.StatusInternalServerError, ""))
 			}
 		}()
 
 		w.Header().Set("Content-Type", "application/json")
 
-		if err := f(ctx, w, r); err != nil {
-			var verr *app.ValidationError
-			var uerr *app.UserError
-
-			switch {
-			case errors.As(err, &verr):
-				handleValidationError(ctx, w, verr)
-			case errors.As(err, &uerr):
-				handleUserError(ctx, w, uerr)
-			default:
-				ctx.Logger.Error(err)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
-			}
+		admitted, err := a.admit(ctx, w, r, routeName)
+		ctx = admitted
+		if err != nil {
+			writeError(ctx, w, r, err)
+			return
+		}
+
+		if err = f(ctx, w, r); err != nil {
+			writeError(ctx, w, r, err)
 		}
 	})
 }
 
-func handleValidationError(ctx *app.Context, w http.ResponseWriter, verr *app.ValidationError) {
-	data, err := json.Marshal(verr)
-	if err == nil {
-		w.WriteHeader(http.StatusBadRequest)
-		_, err = w.Write(data)
+// admit decides whether the request to the named route may be served. Requests are limited by the address of the
+// client before authentication, so that requests failing authentication are limited too, then by the principal once
+// authenticated and bound to its tenant. The context is returned as far as it was prepared, even upon refusal.
+func (a *API) admit(ctx *app.Context, w http.ResponseWriter, r *http.Request, route string) (*app.Context, error) {
+	if err := a.limitAddress(ctx, w, route); err != nil {
+		return ctx, err
 	}
 
+	authenticated, err := a.authenticate(ctx, w, r, route)
 	if err != nil {
-		ctx.Logger.Error(err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
-	}
-}
-
-func handleUserError(ctx *app.Context, w http.ResponseWriter, uerr *app.UserError) {
-	data, err := json.Marshal(uerr)
-	if err == nil {
-		w.WriteHeader(uerr.StatusCode)
-		_, err = w.Write(data)
+		return ctx, err
 	}
+	ctx = authenticated
 
-	if err != nil {
-		ctx.Logger.Error(err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+	if route != "" {
+		var tenanted *app.Context
+		if tenanted, err = a.resolveTenant(ctx, r); err != nil {
+			return ctx, err
+		}
+		ctx = tenanted
 	}
+	return ctx, a.limitRate(ctx, w, route)
 }
 
 func (a *API) helloHandler(ctx *app.Context, w http.ResponseWriter, _ *http.Request) error {
@@ -144,8 +207,3 @@ func (a *API) helloHandler(ctx *app.Context, w http.ResponseWriter, _ *http.Requ
 			ctx.RemoteAddress, ctx.TraceID)))
 	return err
 }
-
-func (a *API) addressForRequest(r *http.Request) string {
-	addr := r.RemoteAddr
-	return addr[:strings.LastIndex(addr, ":")]
-}
@@ -1,14 +1,34 @@
 package api
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+	"github.com/weesvc/weesvc-gorilla/testhelpers"
 )
 
 func TestAddressForRequest(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
 		remoteAddr string
+		header     http.Header
 		expected   string
 	}{
 		{
@@ -17,15 +37,69 @@ func TestAddressForRequest(t *testing.T) {
 		},
 		{
 			remoteAddr: "[::1]:8080",
-			expected:   "[::1]",
+			expected:   "::1",
+		},
+		{
+			remoteAddr: "192.0.2.1",
+			expected:   "192.0.2.1",
+		},
+		{
+			remoteAddr: "@",
+			expected:   "@",
+		},
+		{
+			remoteAddr: "",
+			expected:   "",
+		},
+		{
+			remoteAddr: "192.0.2.1:8080",
+			header:     http.Header{"X-Forwarded-For": {"203.0.113.7"}},
+			expected:   "192.0.2.1",
+		},
+		{
+			remoteAddr: "10.0.0.2:8080",
+			header:     http.Header{"X-Forwarded-For": {"198.51.100.1, 203.0.113.7", "10.0.0.9"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			remoteAddr: "10.0.0.2:8080",
+			header:     http.Header{"X-Forwarded-For": {"10.0.0.8, 10.0.0.9"}},
+			expected:   "10.0.0.8",
+		},
+		{
+			remoteAddr: "10.0.0.2:8080",
+			header:     http.Header{"X-Real-Ip": {"203.0.113.7"}},
+			expected:   "203.0.113.7",
+		},
+		{
+			remoteAddr: "[::ffff:10.0.0.2]:8080",
+			header: http.Header{
+				"Forwarded":       {`for=198.51.100.1, for="[2001:db8::1]:4711";proto=https`},
+				"X-Forwarded-For": {"203.0.113.7"},
+			},
+			expected: "2001:db8::1",
+		},
+		{
+			remoteAddr: "10.0.0.2:8080",
+			header:     http.Header{"Forwarded": {"for=unknown"}},
+			expected:   "10.0.0.2",
+		},
+		{
+			remoteAddr: "10.0.0.2:8080",
+			expected:   "10.0.0.2",
 		},
 	}
-	fixture := API{Config: &Config{}}
+	trustedProxies, err := parseTrustedProxies([]string{"10.0.0.0/8", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := API{Config: &Config{}, trustedProxies: trustedProxies}
 	for _, tc := range testCases {
 		tc := tc // pin
-		t.Run(tc.remoteAddr, func(t *testing.T) {
+		t.Run(tc.remoteAddr+" "+fmt.Sprint(tc.header), func(t *testing.T) {
 			t.Parallel()
 			request := mockRequest(tc.remoteAddr)
+			request.Header = tc.header
 			actual := fixture.addressForRequest(&request)
 			if actual != tc.expected {
 				t.Fatalf("expected %q, but got %q", tc.expected, actual)
@@ -34,6 +108,930 @@ func TestAddressForRequest(t *testing.T) {
 	}
 }
 
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+	if _, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"}); err != nil {
+		t.Fatalf("expected addresses and ranges to be accepted, but got %v", err)
+	}
+	if _, err := parseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected an invalid range to be refused")
+	}
+}
+
 func mockRequest(address string) http.Request {
 	return http.Request{RemoteAddr: address}
 }
+
+func TestPlaceQueryFromRequest(t *testing.T) {
+	t.Parallel()
+	fixture := API{Config: &Config{DefaultPageSize: 100, MaxPageSize: 1000}}
+
+	testCases := []struct {
+		rawQuery   string
+		limit      int
+		sort       db.PlaceSort
+		descending bool
+		invalid    bool
+	}{
+		{rawQuery: "", limit: 100, sort: db.SortByCreatedAt},
+		{rawQuery: "limit=5&sort=-name", limit: 5, sort: db.SortByName, descending: true},
+		{rawQuery: "limit=5000&sort=updated_at", limit: 1000, sort: db.SortByUpdatedAt},
+		{rawQuery: "limit=0", invalid: true},
+		{rawQuery: "limit=ten", invalid: true},
+		{rawQuery: "sort=description", invalid: true},
+		{rawQuery: "cursor=bogus", invalid: true},
+		{rawQuery: "bbox=-87.6,24.5,-80,31", limit: 100, sort: db.SortByCreatedAt},
+		{rawQuery: "bbox=-87.6,31,-80,24.5", invalid: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.rawQuery, func(t *testing.T) {
+			t.Parallel()
+			request := &http.Request{URL: &url.URL{Path: "/api/places", RawQuery: tc.rawQuery}}
+			query, err := fixture.placeQueryFromRequest(request)
+			if tc.invalid {
+				var verr *app.ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("expected validation error, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query.Limit != tc.limit || query.Sort != tc.sort || query.Descending != tc.descending {
+				t.Fatalf("unexpected query %+v", query)
+			}
+		})
+	}
+}
+
+func TestGetPlacesNear_Invalid(t *testing.T) {
+	t.Parallel()
+	fixture := API{Config: &Config{MaxSearchRadius: 500_000}}
+
+	for _, rawQuery := range []string{
+		"near=28.5,-81.4",
+		"near=28.5,-81.4&radius=far",
+		"near=28.5,-81.4&radius=501km",
+		"near=28.5,-81.4&radius=400mi",
+		"near=north&radius=5km",
+	} {
+		rawQuery := rawQuery // pin
+		t.Run(rawQuery, func(t *testing.T) {
+			t.Parallel()
+			request := &http.Request{URL: &url.URL{Path: "/api/places", RawQuery: rawQuery}}
+			var verr *app.ValidationError
+			if _, err := fixture.getPlacesNear(&app.Context{}, request, &db.PlaceQuery{}); !errors.As(err, &verr) {
+				t.Fatalf("expected validation error, but got %v", err)
+			}
+		})
+	}
+}
+
+func TestAcceptsGeoJSON(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		accept   string
+		expected bool
+	}{
+		{accept: "", expected: false},
+		{accept: "application/json", expected: false},
+		{accept: "application/geo+json", expected: true},
+		{accept: "application/json;q=0.5, application/geo+json", expected: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.accept, func(t *testing.T) {
+			t.Parallel()
+			request := &http.Request{Header: http.Header{"Accept": []string{tc.accept}}}
+			if actual := acceptsGeoJSON(request); actual != tc.expected {
+				t.Fatalf("expected %v, but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPlaceFromCreateBody(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		invalid     bool
+	}{
+		{name: "input", body: `{"name":"MCO","latitude":28.42461,"longitude":-81.31075}`},
+		{name: "unknown field", body: `{"name":"MCO","lat":28.42461}`, invalid: true},
+		{
+			name: "feature",
+			body: `{"type":"Feature","geometry":{"type":"Point","coordinates":[-81.31075,28.42461]},` +
+				`"properties":{"name":"MCO"}}`,
+		},
+		{
+			name:        "feature with foreign members",
+			contentType: geoJSONMediaType,
+			body: `{"type":"Feature","id":"mco","bbox":[-81.31075,28.42461,-81.31075,28.42461],` +
+				`"geometry":{"type":"Point","coordinates":[-81.31075,28.42461]},` +
+				`"properties":{"name":"MCO","iata":"MCO"},"source":"survey"}`,
+		},
+		{
+			name:        "feature without point",
+			contentType: geoJSONMediaType,
+			body:        `{"type":"Feature","geometry":null,"properties":{"name":"MCO"}}`,
+			invalid:     true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			request := httptest.NewRequest(http.MethodPost, "/places", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				request.Header.Set("Content-Type", tc.contentType)
+			}
+			place, err := placeFromCreateBody(request)
+			if tc.invalid {
+				var verr *app.ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("expected validation error, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if place.Name != "MCO" || place.Latitude != 28.42461 || place.Longitude != -81.31075 {
+				t.Fatalf("unexpected place %+v", place)
+			}
+		})
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		header   string
+		weak     bool
+		expected bool
+	}{
+		{header: `"1-2"`, expected: true},
+		{header: `"1-1"`, expected: false},
+		{header: `*`, expected: true},
+		{header: `"1-1", "1-2"`, expected: true},
+		{header: `W/"1-2"`, weak: false, expected: false},
+		{header: `W/"1-2"`, weak: true, expected: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.header, func(t *testing.T) {
+			t.Parallel()
+			if actual := matchesETag(tc.header, `"1-2"`, tc.weak); actual != tc.expected {
+				t.Fatalf("expected %v, but got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDatabaseUserError(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{err: fmt.Errorf("unable to get place: %w", db.ErrNotFound), expected: http.StatusNotFound},
+		{err: fmt.Errorf("unable to create place: %w", db.ErrConflict), expected: http.StatusConflict},
+		{err: fmt.Errorf("unable to create place: %w", db.ErrConstraint), expected: http.StatusUnprocessableEntity},
+		{err: fmt.Errorf("unable to update place: %w", db.ErrVersionMismatch), expected: http.StatusConflict},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			t.Parallel()
+			uerr := databaseUserError(tc.err)
+			if uerr == nil || uerr.StatusCode != tc.expected {
+				t.Fatalf("expected status %d, but got %+v", tc.expected, uerr)
+			}
+		})
+	}
+
+	if uerr := databaseUserError(errors.New("connection refused")); uerr != nil {
+		t.Fatalf("expected no user error, but got %+v", uerr)
+	}
+}
+
+func TestHandler_Problem(t *testing.T) {
+	t.Parallel()
+	fixture := API{App: &app.App{}, Config: &Config{}}
+
+	testCases := []struct {
+		name    string
+		handler handlerFunc
+		status  int
+		typeURI string
+		field   string
+	}{
+		{
+			name: "validation",
+			handler: func(*app.Context, http.ResponseWriter, *http.Request) error {
+				return app.NewFieldError("name", "is required")
+			},
+			status:  http.StatusBadRequest,
+			typeURI: problemTypeValidation,
+			field:   "name",
+		},
+		{
+			name: "not found",
+			handler: func(*app.Context, http.ResponseWriter, *http.Request) error {
+				return fmt.Errorf("unable to get place: %w", db.ErrNotFound)
+			},
+			status:  http.StatusNotFound,
+			typeURI: problemTypeDefault,
+		},
+		{
+			name: "internal",
+			handler: func(*app.Context, http.ResponseWriter, *http.Request) error {
+				return errors.New("connection refused")
+			},
+			status:  http.StatusInternalServerError,
+			typeURI: problemTypeDefault,
+		},
+		{
+			name: "panic",
+			handler: func(*app.Context, http.ResponseWriter, *http.Request) error {
+				panic("unexpected")
+			},
+			status:  http.StatusInternalServerError,
+			typeURI: problemTypeDefault,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			recorder := httptest.NewRecorder()
+			fixture.handler(tc.handler).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/places/1", nil))
+
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, but got %d", tc.status, recorder.Code)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != problemMediaType {
+				t.Fatalf("expected %s, but got %s", problemMediaType, contentType)
+			}
+
+			var actual problem
+			if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil {
+				t.Fatal(err)
+			}
+			if actual.Type != tc.typeURI || actual.Status != tc.status || actual.Title == "" {
+				t.Fatalf("unexpected problem %+v", actual)
+			}
+			if actual.Instance != "/api/places/1" || actual.TraceID == "" {
+				t.Fatalf("expected problem to identify the request, but got %+v", actual)
+			}
+			if tc.field != "" && (len(actual.Errors) != 1 || actual.Errors[0].Field != tc.field) {
+				t.Fatalf("expected error for field %s, but got %+v", tc.field, actual.Errors)
+			}
+		})
+	}
+}
+
+func TestHandler_VersionMismatch(t *testing.T) {
+	t.Parallel()
+	fixture := API{App: &app.App{}, Config: &Config{}}
+	handler := fixture.handler(func(*app.Context, http.ResponseWriter, *http.Request) error {
+		return fmt.Errorf("unable to update place: %w", db.ErrVersionMismatch)
+	})
+
+	for ifMatch, expected := range map[string]int{"": http.StatusConflict, `"1-1"`: http.StatusPreconditionFailed} {
+		request := httptest.NewRequest(http.MethodPatch, "/api/places/1", nil)
+		if ifMatch != "" {
+			request.Header.Set("If-Match", ifMatch)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != expected {
+			t.Fatalf("expected status %d with If-Match %q, but got %d", expected, ifMatch, recorder.Code)
+		}
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		body  string
+		field string
+	}{
+		{body: `{"name":"Hoover Dam","latitude":36.01604}`},
+		{body: `{"name":"Hoover Dam"}` + "\n"},
+		{body: ``, field: "body"},
+		{body: `{"name":`, field: "body"},
+		{body: `{"name" "Hoover Dam"}`, field: "body"},
+		{body: `{"name":"Hoover Dam"} {}`, field: "body"},
+		{body: `["Hoover Dam"]`, field: "body"},
+		{body: `{"latitude":"36.01604"}`, field: "latitude"},
+		{body: `{"colour":"red"}`, field: "colour"},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.body, func(t *testing.T) {
+			t.Parallel()
+			var input createPlaceInput
+			err := decodeJSON(strings.NewReader(tc.body), &input)
+			if tc.field == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var verr *app.ValidationError
+			if !errors.As(err, &verr) || len(verr.Errors) != 1 || verr.Errors[0].Field != tc.field {
+				t.Fatalf("expected validation error for %s, but got %v", tc.field, err)
+			}
+		})
+	}
+}
+
+func TestConfig_MaxBodyBytes(t *testing.T) {
+	t.Parallel()
+	config := &Config{MaxBodyBytes: 100, RouteMaxBodyBytes: map[string]int64{"create_place": 10}}
+	if limit := config.maxBodyBytes("create_place"); limit != 10 {
+		t.Fatalf("expected route limit, but got %d", limit)
+	}
+	if limit := config.maxBodyBytes("batch_places"); limit != 100 {
+		t.Fatalf("expected default limit, but got %d", limit)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+	if err := (&Config{AuthMethod: authMethodNone}).validate(); err == nil {
+		t.Fatal("expected open routes to require opting in")
+	}
+	if err := (&Config{AuthMethod: authMethodNone, AllowUnauthenticated: true}).validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Config{AuthMethod: authMethodAPIKey}).validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stubAuthenticator grants the scopes named by the `X-Scopes` header, refusing requests lacking the header.
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(_ *app.Context, r *http.Request) (*app.Principal, error) {
+	scopes := r.Header.Get("X-Scopes")
+	if scopes == "" {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "credentials are required"}
+	}
+	return &app.Principal{ID: "stub", Scopes: strings.Fields(scopes)}, nil
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	t.Parallel()
+	fixture := API{App: &app.App{}, Config: &Config{}, Authenticator: stubAuthenticator{}}
+	ok := func(ctx *app.Context, w http.ResponseWriter, _ *http.Request) error {
+		if ctx.Principal != nil {
+			w.Header().Set("X-Principal", ctx.Principal.ID)
+		}
+		return nil
+	}
+	router := mux.NewRouter()
+	router.Handle("/places/1", fixture.handler(ok)).Methods("GET").Name("get_place")
+	router.Handle("/places/1", fixture.handler(ok)).Methods("PATCH").Name("update_place")
+	router.Handle("/unlisted", fixture.handler(ok)).Name("unlisted")
+	router.Handle("/hello", fixture.handler(ok))
+
+	testCases := []struct {
+		method, path, scopes string
+		status               int
+	}{
+		{method: http.MethodGet, path: "/places/1", scopes: "", status: http.StatusUnauthorized},
+		{method: http.MethodGet, path: "/places/1", scopes: "places:read", status: http.StatusOK},
+		{method: http.MethodPatch, path: "/places/1", scopes: "places:read", status: http.StatusForbidden},
+		{method: http.MethodPatch, path: "/places/1", scopes: "places:read places:write", status: http.StatusOK},
+		{method: http.MethodPatch, path: "/places/1", scopes: "admin", status: http.StatusOK},
+		{method: http.MethodGet, path: "/unlisted", scopes: "places:write", status: http.StatusForbidden},
+		{method: http.MethodGet, path: "/unlisted", scopes: "admin", status: http.StatusOK},
+		{method: http.MethodGet, path: "/hello", scopes: "", status: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.method+" "+tc.path+" "+tc.scopes, func(t *testing.T) {
+			t.Parallel()
+			request := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.scopes != "" {
+				request.Header.Set("X-Scopes", tc.scopes)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.status {
+				t.Fatalf("expected status %d, but got %d: %s", tc.status, recorder.Code, recorder.Body)
+			}
+			if tc.status == http.StatusUnauthorized && recorder.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("expected a WWW-Authenticate challenge")
+			}
+			if principal := recorder.Header().Get("X-Principal"); tc.status == http.StatusOK && tc.scopes != "" &&
+				principal != "stub" {
+				t.Fatalf("expected the principal to be attached, but got %q", principal)
+			}
+		})
+	}
+}
+
+// hs256Token creates a token having the claims, signed using the secret.
+func hs256Token(secret string, claims map[string]interface{}) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	payload, _ := json.Marshal(claims)
+	signed := encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + encode(mac.Sum(nil))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	t.Parallel()
+	const secret = "an HS256 secret of at least 32 bytes"
+	const issuer = "https://gateway.example.com"
+	for _, config := range []*Config{
+		{JWTIssuer: issuer, JWTAudience: "weesvc"},
+		{JWTSecret: secret, JWTAudience: "weesvc"},
+		{JWTSecret: secret, JWTIssuer: issuer},
+		{JWTSecret: "secret", JWTIssuer: issuer, JWTAudience: "weesvc"},
+	} {
+		if _, err := newJWTAuthenticator(config); err == nil {
+			t.Fatalf("expected %+v to be refused", config)
+		}
+	}
+	authenticator, err := newJWTAuthenticator(&Config{JWTSecret: secret, JWTIssuer: issuer, JWTAudience: "weesvc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	testCases := []struct {
+		name     string
+		token    string
+		expected *app.Principal
+	}{
+		{
+			name:     "scope",
+			token:    hs256Token(secret, map[string]interface{}{"iss": issuer, "sub": "ann", "aud": "weesvc", "exp": exp, "scope": "places:read admin"}),
+			expected: &app.Principal{ID: "jwt:ann", Scopes: []string{"places:read", "admin"}, Role: app.RoleAdmin},
+		},
+		{
+			name:     "scp",
+			token:    hs256Token(secret, map[string]interface{}{"iss": issuer, "sub": "bob", "aud": "weesvc", "exp": exp, "scp": []string{"places:write"}, "name": "Bob"}),
+			expected: &app.Principal{ID: "jwt:bob", Name: "Bob", Scopes: []string{"places:write"}, Role: app.RoleEditor},
+		},
+		{
+			name:     "role",
+			token:    hs256Token(secret, map[string]interface{}{"iss": issuer, "sub": "cy", "aud": "weesvc", "exp": exp, "role": "viewer", "scope": "admin"}),
+			expected: &app.Principal{ID: "jwt:cy", Scopes: []string{"admin"}, Role: app.RoleViewer},
+		},
+		{name: "missing", token: ""},
+		{name: "wrong issuer", token: hs256Token(secret, map[string]interface{}{"iss": "other", "sub": "ann", "aud": "weesvc", "exp": exp})},
+		{name: "wrong audience", token: hs256Token(secret, map[string]interface{}{"iss": issuer, "sub": "ann", "aud": "other", "exp": exp})},
+		{name: "wrong secret", token: hs256Token("other", map[string]interface{}{"iss": issuer, "sub": "ann", "aud": "weesvc", "exp": exp})},
+		{name: "no subject", token: hs256Token(secret, map[string]interface{}{"iss": issuer, "aud": "weesvc", "exp": exp})},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			request := httptest.NewRequest(http.MethodGet, "/api/places", nil)
+			if tc.token != "" {
+				request.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			principal, err := authenticator.Authenticate(nil, request)
+			if tc.expected == nil {
+				var uerr *app.UserError
+				if !errors.As(err, &uerr) || uerr.StatusCode != http.StatusUnauthorized {
+					t.Fatalf("expected unauthorized, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(principal, tc.expected) {
+				t.Fatalf("expected %+v, but got %+v", tc.expected, principal)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Take(t *testing.T) {
+	t.Parallel()
+	limiter := newRateLimiter()
+	limit := RateLimit{RequestsPerSecond: 2, Burst: 3}
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if decision := limiter.take("client", limit, start); !decision.allowed || decision.remaining != 2-i {
+			t.Fatalf("expected request %d to be allowed with %d remaining, but got %+v", i, 2-i, decision)
+		}
+	}
+	decision := limiter.take("client", limit, start)
+	if decision.allowed || decision.retryAfter != 500*time.Millisecond || decision.reset != 1500*time.Millisecond {
+		t.Fatalf("expected the request to be refused for 500ms, but got %+v", decision)
+	}
+	if decision = limiter.take("other", limit, start); !decision.allowed {
+		t.Fatalf("expected other clients to have their own bucket, but got %+v", decision)
+	}
+	if decision = limiter.take("client", limit, start.Add(time.Second)); !decision.allowed || decision.remaining != 1 {
+		t.Fatalf("expected the bucket to refill, but got %+v", decision)
+	}
+}
+
+func TestHandler_RateLimit(t *testing.T) {
+	t.Parallel()
+	fixture := API{
+		App:     &app.App{},
+		Config:  &Config{RateLimits: map[string]RateLimit{rateGroupRead: {RequestsPerSecond: 0.5, Burst: 2}}},
+		limiter: newRateLimiter(),
+	}
+	ok := func(*app.Context, http.ResponseWriter, *http.Request) error {
+		return nil
+	}
+	router := mux.NewRouter()
+	router.Handle("/places", fixture.handler(ok)).Methods("GET").Name("get_places")
+	router.Handle("/places", fixture.handler(ok)).Methods("POST").Name("create_place")
+
+	request := func(method string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, "/places", nil))
+		return recorder
+	}
+	for _, remaining := range []string{"1", "0"} {
+		recorder := request(http.MethodGet)
+		if recorder.Code != http.StatusOK || recorder.Header().Get("RateLimit-Remaining") != remaining {
+			t.Fatalf("expected 200 with %s remaining, but got %d with %q", remaining, recorder.Code,
+				recorder.Header().Get("RateLimit-Remaining"))
+		}
+	}
+
+	recorder := request(http.MethodGet)
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 retrying after 2 seconds, but got %d after %q: %s", recorder.Code,
+			recorder.Header().Get("Retry-After"), recorder.Body)
+	}
+	if recorder = request(http.MethodPost); recorder.Code != http.StatusOK || recorder.Header().Get("RateLimit-Limit") != "" {
+		t.Fatalf("expected routes of other groups to be unlimited, but got %d", recorder.Code)
+	}
+}
+
+func TestHandler_RateLimit_Unauthenticated(t *testing.T) {
+	t.Parallel()
+	fixture := API{
+		App:           &app.App{},
+		Config:        &Config{RateLimits: map[string]RateLimit{rateGroupRead: {RequestsPerSecond: 0.5, Burst: 2}}},
+		Authenticator: stubAuthenticator{},
+		limiter:       newRateLimiter(),
+	}
+	ok := func(*app.Context, http.ResponseWriter, *http.Request) error {
+		return nil
+	}
+	router := mux.NewRouter()
+	router.Handle("/places", fixture.handler(ok)).Methods("GET").Name("get_places")
+
+	// Requests failing authentication are limited by the address of the client
+	for _, status := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/places", nil))
+		if recorder.Code != status {
+			t.Fatalf("expected %d, but got %d: %s", status, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestHandler_GetPlaces_EmptyNear(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{DefaultPageSize: 100, MaxPageSize: 1000, MaxSearchRadius: 500_000})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/places?near=&radius=5km", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, but got %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestHandler_GetNearestPlaces(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		rawQuery  string
+		maxRadius float64
+		ids       []uint
+	}{
+		// MIA is found within the first radius, while MCO lies over 300km away
+		{name: "radius doubles", rawQuery: "k=2", maxRadius: 500_000, ids: []uint{6, 3}},
+		{name: "excluded", rawQuery: "k=2&exclude_id=6", maxRadius: 500_000, ids: []uint{3, 9}},
+		{name: "bounded by k", rawQuery: "k=3&exclude_id=6", ids: []uint{3, 9, 10}},
+		{name: "bounded by radius", rawQuery: "k=3&exclude_id=6", maxRadius: 500_000, ids: []uint{3, 9}},
+		{name: "globe", rawQuery: "k=100", ids: []uint{6, 3, 9, 10, 5, 1, 8, 4, 2, 7}},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			router := setupRouter(t, &Config{MaxPageSize: 1000, MaxSearchRadius: tc.maxRadius})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet,
+				"/places/nearest?lat=25.79516&lon=-80.27959&"+tc.rawQuery, nil))
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected 200, but got %d: %s", recorder.Code, recorder.Body)
+			}
+
+			var places []*model.Place
+			if err := json.NewDecoder(recorder.Body).Decode(&places); err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]uint, len(places))
+			for i, place := range places {
+				ids[i] = place.ID
+				if i > 0 && *place.Distance < *places[i-1].Distance {
+					t.Fatalf("expected places ordered by distance, but got %v after %v", *place.Distance,
+						*places[i-1].Distance)
+				}
+			}
+			if !reflect.DeepEqual(ids, tc.ids) {
+				t.Fatalf("expected places %v, but got %v", tc.ids, ids)
+			}
+		})
+	}
+}
+
+func TestHandler_VaryAccept(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{DefaultPageSize: 100, MaxPageSize: 1000, MaxSearchRadius: 500_000})
+
+	for _, target := range []string{
+		"/places",
+		"/places?near=28.5,-81.4&radius=30km",
+		"/places/nearest?lat=28.5&lon=-81.4",
+		"/places/trash",
+		"/places/1",
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		if recorder.Code != http.StatusOK || recorder.Header().Get("Vary") != "Accept" {
+			t.Fatalf("expected %s to vary by Accept, but got %d varying by %q", target, recorder.Code,
+				recorder.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestHandler_ETag(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{MaxBodyBytes: 1 << 20})
+	serve := func(method, accept string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, "/places/1", strings.NewReader(`{"description":"Keystone, SD"}`))
+		request.Header = header
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	jsonTag := serve(http.MethodGet, "", http.Header{}).Header().Get("ETag")
+	geoTag := serve(http.MethodGet, "application/geo+json", http.Header{}).Header().Get("ETag")
+	if jsonTag == "" || geoTag == "" || jsonTag == geoTag {
+		t.Fatalf("expected distinct tags for each representation, but got %s and %s", jsonTag, geoTag)
+	}
+
+	// A tag only identifies the representation it was sent with
+	if recorder := serve(http.MethodGet, "application/geo+json", http.Header{"If-None-Match": {jsonTag}}); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, but got %d", recorder.Code)
+	}
+	if recorder := serve(http.MethodGet, "application/geo+json", http.Header{"If-None-Match": {geoTag}}); recorder.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, but got %d", recorder.Code)
+	}
+	// Either identifies the version when changing the place
+	if recorder := serve(http.MethodPatch, "", http.Header{"If-Match": {geoTag}}); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, but got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(http.MethodPatch, "", http.Header{"If-Match": {jsonTag}}); recorder.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412, but got %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+func TestHandler_UpdateTag_TouchesPlaces(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{MaxBodyBytes: 1 << 20})
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	tag := serve(http.MethodGet, "/places/1", "").Header().Get("ETag")
+	if recorder := serve(http.MethodPatch, "/tags/4", `{"name":"national park"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, but got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(http.MethodGet, "/places/1", ""); recorder.Header().Get("ETag") == tag {
+		t.Fatalf("expected the place to change along with its tag, but it remains %s", tag)
+	}
+	if history := serve(http.MethodGet, "/places/1/history", "").Body.String(); !strings.Contains(history, "national park") {
+		t.Fatalf("expected the renamed tag within the history of the place, but got %s", history)
+	}
+}
+
+func TestHandler_Tenant_Unauthenticated(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{})
+
+	for tenant, expected := range map[string]int{
+		"":                  http.StatusOK,
+		model.DefaultTenant: http.StatusOK,
+		"acme":              http.StatusForbidden,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/places", nil)
+		request.Header.Set("X-Tenant", tenant)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != expected {
+			t.Fatalf("expected status %d for tenant %q, but got %d: %s", expected, tenant, recorder.Code, recorder.Body)
+		}
+	}
+}
+
+func TestHandler_ExportPlaces(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/places/export", nil))
+	if recorder.Code != http.StatusOK || !recorder.Flushed {
+		t.Fatalf("expected a flushed 200, but got %d: %s", recorder.Code, recorder.Body)
+	}
+	if lines := strings.Count(recorder.Body.String(), "\n"); lines != 10 {
+		t.Fatalf("expected 10 places, but got %d", lines)
+	}
+	if !strings.Contains(recorder.Body.String(), `"tags":["airport","fl"]`) {
+		t.Fatalf("expected places to be exported with their tags, but got %s", recorder.Body)
+	}
+
+	// Losing the connection during the export fails only once the buffered places are written
+	recovered := func() (recovered interface{}) {
+		defer func() {
+			recovered = recover()
+		}()
+		router.ServeHTTP(brokenWriter{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/places/export", nil))
+		return nil
+	}()
+	if recovered != http.ErrAbortHandler {
+		t.Fatalf("expected the export to be aborted, but got %v", recovered)
+	}
+}
+
+// brokenWriter is a response writer whose connection has been lost.
+type brokenWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestHandler_BatchPlaces(t *testing.T) {
+	t.Parallel()
+	operations := `"operations":[
+		{"op":"create","place":{"name":"Epcot","latitude":28.37468,"longitude":-81.54942}},
+		{"op":"update","id":1,"place":{"name":"Zzyzx Road","description":"Mojave Desert, CA, USA"}},
+		{"op":"delete","id":3},
+		{"op":"update","id":99,"place":{"name":"Nowhere"}},
+		{"op":"create","place":{"name":"Magic Kingdom","latitude":28.41772,"longitude":-81.58118}}
+	]`
+	testCases := []struct {
+		mode      string
+		status    int
+		committed bool
+		statuses  []int
+		total     string
+		name      string
+		// similar is the number of places similar to the name of place 1 before the batch, found by the similarity index
+		similar string
+	}{
+		{
+			mode:     batchAtomic,
+			status:   http.StatusNotFound,
+			statuses: []int{424, 424, 424, 404, 424},
+			total:    "10",
+			name:     "Mount Rushmore",
+			similar:  "1",
+		},
+		{
+			mode:      batchBestEffort,
+			status:    http.StatusOK,
+			committed: true,
+			statuses:  []int{200, 200, 200, 404, 200},
+			total:     "11",
+			name:      "Zzyzx Road",
+			similar:   "0",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.mode, func(t *testing.T) {
+			t.Parallel()
+			router := setupRouter(t, &Config{DefaultPageSize: 100, MaxPageSize: 1000, MaxBatchSize: 10, MaxBodyBytes: 1 << 20})
+			serve := func(method, target, body string) *httptest.ResponseRecorder {
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+				return recorder
+			}
+
+			// The similarity index is built upon its first use, so is built before the batch changes it
+			serve(http.MethodGet, "/places?fuzzy=Mount+Rushmore", "")
+
+			recorder := serve(http.MethodPost, "/places:batch", `{"mode":"`+tc.mode+`",`+operations+`}`)
+			var response batchResponse
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			statuses := make([]int, len(response.Results))
+			for i, result := range response.Results {
+				statuses[i] = result.Status
+			}
+			if recorder.Code != tc.status || response.Committed != tc.committed || !reflect.DeepEqual(statuses, tc.statuses) {
+				t.Fatalf("expected %d committing %v with results %v, but got %d committing %v with results %v",
+					tc.status, tc.committed, tc.statuses, recorder.Code, response.Committed, statuses)
+			}
+
+			if recorder = serve(http.MethodGet, "/places", ""); recorder.Header().Get("X-Total-Count") != tc.total {
+				t.Fatalf("expected %s places, but got %q", tc.total, recorder.Header().Get("X-Total-Count"))
+			}
+			var place model.Place
+			if err := json.NewDecoder(serve(http.MethodGet, "/places/1", "").Body).Decode(&place); err != nil {
+				t.Fatal(err)
+			}
+			if place.Name != tc.name {
+				t.Fatalf("expected place 1 to be named %q, but got %q", tc.name, place.Name)
+			}
+			if recorder = serve(http.MethodGet, "/places?fuzzy=Mount+Rushmore", ""); recorder.Header().Get("X-Total-Count") != tc.similar {
+				t.Fatalf("expected %s similar places, but got %q", tc.similar, recorder.Header().Get("X-Total-Count"))
+			}
+		})
+	}
+}
+
+func TestHandler_IdempotentRevert(t *testing.T) {
+	t.Parallel()
+	router := setupRouter(t, &Config{IdempotencyTTL: time.Hour, MaxBodyBytes: 1 << 20})
+	serve := func(method, target, body, key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, target, strings.NewReader(body))
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	for _, name := range []string{"Rushmore", "Mt. Rushmore", "Mount Rushmore"} {
+		if recorder := serve(http.MethodPatch, "/places/1", `{"name":"`+name+`"}`, ""); recorder.Code != http.StatusOK {
+			t.Fatalf("expected 200, but got %d: %s", recorder.Code, recorder.Body)
+		}
+	}
+
+	if recorder := serve(http.MethodPost, "/places/1/revert?to=3", "", "revert"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, but got %d: %s", recorder.Code, recorder.Body)
+	}
+	if recorder := serve(http.MethodPost, "/places/1/revert?to=3", "", "revert"); recorder.Code != http.StatusOK ||
+		recorder.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected the revert to be replayed, but got %d: %s", recorder.Code, recorder.Body)
+	}
+	// Reverting to another revision is a different request, despite sharing the path and body
+	if recorder := serve(http.MethodPost, "/places/1/revert?to=5", "", "revert"); recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, but got %d: %s", recorder.Code, recorder.Body)
+	}
+}
+
+// setupRouter routes requests to an API backed by a SQLite database within a temporary file, holding the places
+// of the seed data.
+func setupRouter(t *testing.T, config *Config) *mux.Router {
+	t.Helper()
+	placeDB, err := db.New(&db.Config{
+		DatabaseURI: filepath.Join(t.TempDir(), "test.db"),
+		Dialect:     "sqlite3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = placeDB.Close()
+	})
+
+	if err := testhelpers.PrepareDatabase(placeDB.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture := &API{App: &app.App{Database: placeDB}, Config: config}
+	router := mux.NewRouter()
+	fixture.Init(router)
+	return router
+}
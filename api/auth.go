@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Methods of authenticating clients, selected by the `AuthMethod` setting.
+const (
+	// authMethodNone leaves every route open, as when the API is only reachable by trusted clients
+	authMethodNone = "none"
+	// authMethodAPIKey requires clients to present an API key
+	authMethodAPIKey = "apikey"
+	// authMethodJWT requires clients to present a bearer token issued by a trusted issuer, such as a gateway
+	authMethodJWT = "jwt"
+)
+
+// Authenticator identifies the principal making a request from the credentials it carries.
+type Authenticator interface {
+	// Authenticate provides the principal for the request, or an app.UserError when the credentials are missing
+	// or invalid.
+	Authenticate(ctx *app.Context, r *http.Request) (*app.Principal, error)
+}
+
+// newAuthenticator creates the authenticator for the configured method, other than authMethodNone.
+func newAuthenticator(config *Config) (Authenticator, error) {
+	switch strings.ToLower(config.AuthMethod) {
+	case authMethodAPIKey:
+		return apiKeyAuthenticator{}, nil
+	case authMethodJWT:
+		return newJWTAuthenticator(config)
+	default:
+		return nil, fmt.Errorf("unknown AuthMethod %q; must be %q, %q, or %q",
+			config.AuthMethod, authMethodNone, authMethodAPIKey, authMethodJWT)
+	}
+}
+
+// apiKeyAuthenticator accepts API keys given by the `X-API-Key` header or as bearer tokens.
+type apiKeyAuthenticator struct{}
+
+func (apiKeyAuthenticator) Authenticate(ctx *app.Context, r *http.Request) (*app.Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		key = bearerToken(r)
+	}
+	if key == "" {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "an API key is required"}
+	}
+
+	principal, err := ctx.AuthenticateAPIKey(key)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "invalid API key"}
+	}
+	return principal, err
+}
+
+// bearerToken provides the token of a bearer `Authorization` header, if any.
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// routeScopes provides the scope required by each named route. Named routes missing from the map require the admin
+// scope, while unnamed routes, such as `/hello`, are open to all.
+func routeScopes() map[string]string {
+	return map[string]string{
+		"get_places":         model.ScopePlacesRead,
+		"get_nearest_places": model.ScopePlacesRead,
+		"get_place_facets":   model.ScopePlacesRead,
+		"suggest_places":     model.ScopePlacesRead,
+		"get_deleted_places": model.ScopePlacesRead,
+		"export_places":      model.ScopePlacesRead,
+		"get_place":          model.ScopePlacesRead,
+		"get_place_history":  model.ScopePlacesRead,
+		"get_tags":           model.ScopePlacesRead,
+		"get_tag":            model.ScopePlacesRead,
+		"batch_places":       model.ScopePlacesWrite,
+		"create_place":       model.ScopePlacesWrite,
+		"update_place":       model.ScopePlacesWrite,
+		"delete_place":       model.ScopePlacesWrite,
+		"restore_place":      model.ScopePlacesWrite,
+		"revert_place":       model.ScopePlacesWrite,
+	}
+}
+
+// authenticate attaches the principal making the request to the context, ensuring the principal is granted the
+// scope required by the route. Every request is allowed when no authenticator is configured.
+func (a *API) authenticate(
+	ctx *app.Context, w http.ResponseWriter, r *http.Request, route string,
+) (*app.Context, error) {
+	if a.Authenticator == nil || route == "" {
+		return ctx, nil
+	}
+
+	principal, err := a.Authenticator.Authenticate(ctx, r)
+	if err != nil {
+		var uerr *app.UserError
+		if errors.As(err, &uerr) && uerr.StatusCode == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="weesvc"`)
+		}
+		return ctx, err
+	}
+
+	scope, ok := routeScopes()[route]
+	if !ok {
+		scope = model.ScopeAdmin
+	}
+	if !principal.HasScope(scope) {
+		return ctx, &app.UserError{StatusCode: http.StatusForbidden, Message: fmt.Sprintf("the %q scope is required", scope)}
+	}
+	return ctx.WithPrincipal(principal), nil
+}
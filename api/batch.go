@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Supported batch modes.
+const (
+	// batchAtomic applies every operation within a single transaction, or none of them
+	batchAtomic = "atomic"
+	// batchBestEffort applies each operation independently
+	batchBestEffort = "best_effort"
+)
+
+// Supported batch operations.
+const (
+	batchCreate = "create"
+	batchUpdate = "update"
+	batchDelete = "delete"
+)
+
+type batchInput struct {
+	Mode       string            `json:"mode"`
+	Operations []*batchOperation `json:"operations"`
+}
+
+type batchOperation struct {
+	Op    string          `json:"op"`
+	ID    uint            `json:"id,omitempty"`
+	Place json.RawMessage `json:"place,omitempty"`
+}
+
+type batchResult struct {
+	Status int          `json:"status"`
+	Place  *model.Place `json:"place,omitempty"`
+	Error  *problem     `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Committed bool           `json:"committed"`
+	Results   []*batchResult `json:"results"`
+}
+
+// errBatchFailed signals an atomic batch to roll back after an operation has failed.
+var errBatchFailed = errors.New("batch operation failed")
+
+func (a *API) batchPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	var input batchInput
+	if err := decodeBody(r, &input); err != nil {
+		return err
+	}
+	if len(input.Operations) == 0 {
+		return app.NewFieldError("operations", "are required")
+	}
+	if a.Config.MaxBatchSize > 0 && len(input.Operations) > a.Config.MaxBatchSize {
+		return app.NewFieldError("operations", fmt.Sprintf("at most %d are allowed", a.Config.MaxBatchSize))
+	}
+
+	var response *batchResponse
+	var err error
+	switch input.Mode {
+	case batchAtomic, "":
+		response, err = applyAtomicBatch(ctx, input.Operations)
+	case batchBestEffort:
+		response, err = applyBestEffortBatch(ctx, input.Operations)
+	default:
+		return app.NewFieldError("mode", fmt.Sprintf("must be %q or %q", batchAtomic, batchBestEffort))
+	}
+	if err != nil {
+		return err
+	}
+
+	if !response.Committed {
+		// Report the failing operation as the status of the batch
+		for _, result := range response.Results {
+			if result.Error != nil && result.Status != http.StatusFailedDependency {
+				w.WriteHeader(result.Status)
+				break
+			}
+		}
+	}
+	return writeJSON(w, response)
+}
+
+// applyAtomicBatch applies every operation within a single transaction, stopping at the first failure.
+func applyAtomicBatch(ctx *app.Context, operations []*batchOperation) (*batchResponse, error) {
+	response := &batchResponse{Results: make([]*batchResult, len(operations))}
+	err := ctx.Transaction(func(txCtx *app.Context) error {
+		for i, operation := range operations {
+			result, err := applyBatchOperation(txCtx, operation)
+			if err != nil {
+				return err
+			}
+			response.Results[i] = result
+			if result.Error != nil {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBatchFailed) {
+		for i, result := range response.Results {
+			if result == nil || result.Error == nil {
+				response.Results[i] = &batchResult{
+					Status: http.StatusFailedDependency,
+					Error:  newProblem(http.StatusFailedDependency, "not applied; the batch was rolled back"),
+				}
+			}
+		}
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response.Committed = true
+	return response, nil
+}
+
+// applyBestEffortBatch applies each operation independently, continuing past failures.
+func applyBestEffortBatch(ctx *app.Context, operations []*batchOperation) (*batchResponse, error) {
+	response := &batchResponse{Committed: true, Results: make([]*batchResult, len(operations))}
+	for i, operation := range operations {
+		result, err := applyBatchOperation(ctx, operation)
+		if err != nil {
+			ctx.Logger.WithError(err).Error("unable to apply batch operation")
+			result = &batchResult{
+				Status: http.StatusInternalServerError,
+				Error:  newProblem(http.StatusInternalServerError, ""),
+			}
+		}
+		response.Results[i] = result
+	}
+	return response, nil
+}
+
+// applyBatchOperation applies a single operation. Failures attributable to the operation are reported
+// within the result, while any other error is returned.
+func applyBatchOperation(ctx *app.Context, operation *batchOperation) (*batchResult, error) {
+	place, err := dispatchBatchOperation(ctx, operation)
+	switch {
+	case err == nil:
+		return &batchResult{Status: http.StatusOK, Place: place}, nil
+	case errors.Is(err, db.ErrNotFound):
+		err = &app.UserError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("place %d not found", operation.ID),
+		}
+	case errors.Is(err, db.ErrVersionMismatch):
+		err = &app.UserError{
+			StatusCode: http.StatusConflict,
+			Message:    fmt.Sprintf("place %d has been modified", operation.ID),
+		}
+	}
+
+	if p := problemFor(err); p != nil {
+		return &batchResult{Status: p.Status, Error: p}, nil
+	}
+	return nil, err
+}
+
+func dispatchBatchOperation(ctx *app.Context, operation *batchOperation) (*model.Place, error) {
+	switch operation.Op {
+	case batchCreate:
+		var input createPlaceInput
+		if err := decodeOperationPlace(operation, &input); err != nil {
+			return nil, err
+		}
+		place := input.place()
+		return place, ctx.CreatePlace(place)
+
+	case batchUpdate:
+		var input updatePlaceInput
+		if err := decodeOperationPlace(operation, &input); err != nil {
+			return nil, err
+		}
+		place, err := ctx.GetPlaceByID(operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		input.apply(place)
+		return place, ctx.UpdatePlace(place)
+
+	case batchDelete:
+		return nil, ctx.DeletePlaceByID(operation.ID)
+
+	default:
+		return nil, app.NewFieldError("op",
+			fmt.Sprintf("must be one of %q, %q, or %q", batchCreate, batchUpdate, batchDelete))
+	}
+}
+
+// decodeOperationPlace strictly decodes the place given with the operation.
+func decodeOperationPlace(operation *batchOperation, v interface{}) error {
+	if len(operation.Place) == 0 {
+		return app.NewFieldError("place", "is required")
+	}
+	return nestedDecodeError("place", decodeJSON(bytes.NewReader(operation.Place), v))
+}
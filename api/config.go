@@ -1,17 +1,133 @@
 package api
 
-import "github.com/spf13/viper"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+)
 
 // Config provides external settings.
 type Config struct {
 	// The port to bind the web application server to
 	Port int
+	// The number of places returned by listings when a limit is not requested
+	DefaultPageSize int
+	// The largest number of places a client may request within a single page
+	MaxPageSize int
+	// The largest number of operations accepted within a single batch request
+	MaxBatchSize int
+	// The largest radius, in meters, of proximity searches
+	MaxSearchRadius float64
+	// How long the response to a request made with an `Idempotency-Key` is kept for replaying to retries
+	IdempotencyTTL time.Duration
+	// The largest request body accepted, in bytes, unless overridden for the route
+	MaxBodyBytes int64
+	// The largest request body accepted, in bytes, keyed by route name, such as `create_place`
+	RouteMaxBodyBytes map[string]int64
+	// How often the in-process index of similar places is rebuilt for SQLite databases, reflecting changes
+	// made by other processes
+	SimilarityIndexRefresh time.Duration
+	// How clients are authenticated: `none`, leaving every route open, `apikey`, or `jwt`
+	AuthMethod string
+	// Whether every route may be left open by the `none` AuthMethod; otherwise the service refuses to start
+	AllowUnauthenticated bool
+	// The JWKS file holding the keys trusted to sign bearer tokens when authenticating by `jwt`
+	JWTKeysFile string
+	// A secret of at least 32 bytes trusted to sign HS256 bearer tokens when authenticating by `jwt`
+	JWTSecret string
+	// The issuer which must be given by the `iss` claim of bearer tokens
+	JWTIssuer string
+	// The audience which must be named by the `aud` claim of bearer tokens
+	JWTAudience string
+	// The allowance for differences between the clocks of the token issuer and the service
+	JWTLeeway time.Duration
+	// The origins permitted to make cross-origin requests, or `*` for any origin; none are permitted by default
+	AllowedOrigins []string
+	// The limits on the requests of each client, keyed by route group: `read`, `write`, or `admin`
+	RateLimits map[string]RateLimit
+	// The addresses and CIDR ranges of the proxies trusted to report the addresses of clients within the `Forwarded`,
+	// `X-Forwarded-For`, and `X-Real-IP` headers
+	TrustedProxies []string
+}
+
+// defaultRouteMaxBodyBytes limits the bodies of routes accepting a single place, which are far smaller than
+// the bodies of batch requests.
+func defaultRouteMaxBodyBytes() map[string]int64 {
+	return map[string]int64{
+		"create_place": 1 << 20,
+		"update_place": 1 << 20,
+	}
+}
+
+// maxBodyBytes provides the largest request body accepted by the named route.
+func (c *Config) maxBodyBytes(route string) int64 {
+	if limit, ok := c.RouteMaxBodyBytes[strings.ToLower(route)]; ok {
+		return limit
+	}
+	return c.MaxBodyBytes
+}
+
+// validate refuses settings which leave every route open, unless explicitly allowed.
+func (c *Config) validate() error {
+	if strings.EqualFold(c.AuthMethod, authMethodNone) && !c.AllowUnauthenticated {
+		return fmt.Errorf("AuthMethod %q leaves every route open; set AllowUnauthenticated to allow this",
+			authMethodNone)
+	}
+	return nil
 }
 
 func initConfig() *Config {
+	viper.SetDefault("DefaultPageSize", db.DefaultPageSize)
+	viper.SetDefault("MaxPageSize", 1000)
+	viper.SetDefault("MaxBatchSize", 1000)
+	viper.SetDefault("MaxSearchRadius", 500_000)
+	viper.SetDefault("IdempotencyTTL", 24*time.Hour)
+	viper.SetDefault("MaxBodyBytes", 100<<20)
+	viper.SetDefault("SimilarityIndexRefresh", 5*time.Minute)
+	viper.SetDefault("AuthMethod", authMethodNone)
+	viper.SetDefault("JWTLeeway", time.Minute)
+
 	config := &Config{
-		Port: viper.GetInt("Port"),
+		Port:                   viper.GetInt("Port"),
+		DefaultPageSize:        viper.GetInt("DefaultPageSize"),
+		MaxPageSize:            viper.GetInt("MaxPageSize"),
+		MaxBatchSize:           viper.GetInt("MaxBatchSize"),
+		MaxSearchRadius:        viper.GetFloat64("MaxSearchRadius"),
+		IdempotencyTTL:         viper.GetDuration("IdempotencyTTL"),
+		MaxBodyBytes:           viper.GetInt64("MaxBodyBytes"),
+		SimilarityIndexRefresh: viper.GetDuration("SimilarityIndexRefresh"),
+		AuthMethod:             viper.GetString("AuthMethod"),
+		AllowUnauthenticated:   viper.GetBool("AllowUnauthenticated"),
+		JWTKeysFile:            viper.GetString("JWTKeysFile"),
+		JWTSecret:              viper.GetString("JWTSecret"),
+		JWTIssuer:              viper.GetString("JWTIssuer"),
+		JWTAudience:            viper.GetString("JWTAudience"),
+		JWTLeeway:              viper.GetDuration("JWTLeeway"),
+		AllowedOrigins:         viper.GetStringSlice("AllowedOrigins"),
+		TrustedProxies:         viper.GetStringSlice("TrustedProxies"),
+	}
+
+	// Route names are matched regardless of case as settings keys are not case-sensitive
+	config.RouteMaxBodyBytes = defaultRouteMaxBodyBytes()
+	for route, limit := range viper.GetStringMap("RouteMaxBodyBytes") {
+		config.RouteMaxBodyBytes[strings.ToLower(route)] = cast.ToInt64(limit)
+	}
+
+	config.RateLimits = map[string]RateLimit{}
+	for group, value := range viper.GetStringMap("RateLimits") {
+		settings := cast.ToStringMap(value)
+		config.RateLimits[strings.ToLower(group)] = RateLimit{
+			RequestsPerSecond: cast.ToFloat64(settings["requestspersecond"]),
+			Burst:             cast.ToInt(settings["burst"]),
+			DailyQuota:        cast.ToInt(settings["dailyquota"]),
+		}
 	}
+
 	if config.Port == 0 {
 		config.Port = 9092
 	}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+)
+
+// decodeJSON strictly decodes a single JSON value from the reader into v. Unknown fields, values of the wrong
+// type, and any data following the value are rejected with a ValidationError naming the offending field.
+func decodeJSON(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	var trailing json.RawMessage
+	switch err := decoder.Decode(&trailing); {
+	case errors.Is(err, io.EOF):
+		return nil
+	case isMaxBytesError(err):
+		return err
+	default:
+		return app.NewFieldError("body", "must hold a single JSON value")
+	}
+}
+
+// decodeBody strictly decodes the request body into v, as with decodeJSON.
+func decodeBody(r *http.Request, v interface{}) error {
+	defer func() {
+		_ = r.Body.Close()
+	}()
+	return decodeJSON(r.Body, v)
+}
+
+// decodeError describes why a JSON value could not be decoded.
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case isMaxBytesError(err):
+		return err
+	case errors.Is(err, io.EOF):
+		return app.NewFieldError("body", "must not be empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return app.NewFieldError("body", "is not valid JSON: unexpected end of input")
+	case errors.As(err, &syntaxErr):
+		return app.NewFieldError("body", fmt.Sprintf("is not valid JSON at offset %d: %s", syntaxErr.Offset, syntaxErr))
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return app.NewFieldError(field, "must be "+jsonTypeName(typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder reports unknown fields without a dedicated error type
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return app.NewFieldError(field, "is not a recognized field")
+	default:
+		return app.NewFieldError("body", err.Error())
+	}
+}
+
+// nestedDecodeError qualifies the fields of a decoding error as members of the parent field.
+func nestedDecodeError(parent string, err error) error {
+	var verr *app.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	fields := make([]app.FieldError, len(verr.Errors))
+	for i, e := range verr.Errors {
+		fields[i] = app.FieldError{Field: parent, Message: e.Message}
+		if e.Field != "body" {
+			fields[i].Field = parent + "." + e.Field
+		}
+	}
+	return app.NewValidationError(fields)
+}
+
+func isMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// jsonTypeName describes the JSON representation of values of the Go type.
+func jsonTypeName(t reflect.Type) string {
+	switch kind := t.Kind(); {
+	case kind == reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	case kind == reflect.Bool:
+		return "a boolean"
+	case kind >= reflect.Int && kind <= reflect.Uint64:
+		return "an integer"
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return "a number"
+	case kind == reflect.String:
+		return "a string"
+	case kind == reflect.Slice || kind == reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
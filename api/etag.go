@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// etag creates the entity tag identifying the current version of the place within the representation requested.
+func etag(r *http.Request, place *model.Place) string {
+	return representationETag(place, acceptsGeoJSON(r))
+}
+
+// representationETag creates the entity tag identifying the current version of the place within its JSON or GeoJSON
+// representation, as each differs in content.
+func representationETag(place *model.Place, geoJSON bool) string {
+	tag := strconv.FormatUint(uint64(place.ID), 10) + "-" + strconv.FormatUint(uint64(place.Version), 10)
+	if geoJSON {
+		tag += "-geo"
+	}
+	return `"` + tag + `"`
+}
+
+// matchesETag determines whether the entity tag is listed within the header value, which may also be `*`.
+// Weak tags are only considered when weak comparison is permitted.
+func matchesETag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIfMatch ensures the place is at the version required by any `If-Match` header of the request.
+// Tags of either representation are accepted, as both identify the same version.
+func checkIfMatch(r *http.Request, place *model.Place) error {
+	header := r.Header.Get("If-Match")
+	if header == "" ||
+		matchesETag(header, representationETag(place, false), false) ||
+		matchesETag(header, representationETag(place, true), false) {
+		return nil
+	}
+	return &app.UserError{StatusCode: http.StatusPreconditionFailed, Message: "place has been modified"}
+}
+
+// notModified determines whether the client already holds the current version of the place
+// according to the `If-None-Match` header of the request.
+func notModified(r *http.Request, place *model.Place) bool {
+	header := r.Header.Get("If-None-Match")
+	return header != "" && matchesETag(header, etag(r, place), true)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+	"github.com/weesvc/weesvc-gorilla/transfer"
+)
+
+func (a *API) exportPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	format := transfer.FormatJSONL
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		if format, err = transfer.ParseFormat(name); err != nil {
+			return app.NewFieldError("format", err.Error())
+		}
+	}
+
+	writer, err := transfer.NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="places.`+string(format)+`"`)
+
+	written := 0
+	err = ctx.ExportPlaces(func(place *model.Place) error {
+		written++
+		return writer.Write(place)
+	})
+	if err != nil && written == 0 {
+		w.Header().Del("Content-Disposition")
+		return err
+	}
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// The response has already begun, so the connection is aborted rather than ending the response as though
+		// the export were complete
+		ctx.Logger.WithError(err).Error("unable to complete export")
+		panic(http.ErrAbortHandler)
+	}
+
+	if err = http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		ctx.Logger.WithError(err).Warn("unable to flush export")
+	}
+	return nil
+}
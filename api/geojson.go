@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+const geoJSONMediaType = "application/geo+json"
+
+// acceptsGeoJSON determines whether the client has requested the GeoJSON representation.
+func acceptsGeoJSON(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(mediaRange)
+			if err == nil && mediaType == geoJSONMediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isGeoJSON determines whether the request body has been sent as GeoJSON.
+func isGeoJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == geoJSONMediaType
+}
+
+// writePlaces responds with the list of places as JSON, or as a GeoJSON FeatureCollection when requested.
+func writePlaces(w http.ResponseWriter, r *http.Request, places []*model.Place) error {
+	w.Header().Add("Vary", "Accept")
+	if acceptsGeoJSON(r) {
+		w.Header().Set("Content-Type", geoJSONMediaType)
+		return writeJSON(w, model.NewFeatureCollection(places))
+	}
+	return writeJSON(w, places)
+}
+
+// writePlace responds with the place as JSON, or as a GeoJSON Feature when requested.
+func writePlace(w http.ResponseWriter, r *http.Request, place *model.Place) error {
+	if acceptsGeoJSON(r) {
+		w.Header().Set("Content-Type", geoJSONMediaType)
+		return writeJSON(w, model.NewFeature(place))
+	}
+	return writeJSON(w, place)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
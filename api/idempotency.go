@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// maxIdempotencyKeyLength limits the size of keys clients may provide.
+const maxIdempotencyKeyLength = 255
+
+// replayedHeaders lists the response headers recorded for replaying alongside the response body.
+func replayedHeaders() []string {
+	return []string{"Content-Type", "ETag", "Location"}
+}
+
+// responseRecorder captures the response written to the client so that it may be replayed.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// idempotent allows clients to safely retry a mutating request by providing an `Idempotency-Key` header.
+// The response to the first request made with a key is recorded and replayed to any retry of the same request.
+// Reusing a key for a different request fails with 422, and retrying while the first request is still being
+// processed fails with 409. Server errors are not recorded, so such requests may be retried.
+func (a *API) idempotent(f handlerFunc) handlerFunc {
+	return func(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			return f(ctx, w, r)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return &app.ValidationError{Message: "Idempotency-Key is too long"}
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(r, body)
+		existing, err := ctx.ReserveIdempotencyKey(key, fingerprint, a.Config.IdempotencyTTL)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return replay(w, existing, fingerprint)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			// Allow a retry when the request could not be completed, such as from a panic
+			if !completed {
+				if rerr := ctx.ReleaseIdempotencyKey(key); rerr != nil {
+					ctx.Logger.Error(rerr)
+				}
+			}
+		}()
+
+		if ferr := f(ctx, recorder, r); ferr != nil {
+			writeError(ctx, recorder, r, ferr)
+		}
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+		if recorder.statusCode >= http.StatusInternalServerError {
+			return nil
+		}
+
+		if err = ctx.CompleteIdempotencyKey(recordedResponse(key, recorder)); err != nil {
+			ctx.Logger.Error(err)
+			return nil
+		}
+		completed = true
+		return nil
+	}
+}
+
+// recordedResponse captures the response for replaying to retries of the request made with the key.
+func recordedResponse(key string, recorder *responseRecorder) *model.IdempotencyKey {
+	header := make(map[string]string)
+	for _, name := range replayedHeaders() {
+		if value := recorder.Header().Get(name); value != "" {
+			header[name] = value
+		}
+	}
+	// Encoding a map of strings cannot fail
+	encodedHeader, _ := json.Marshal(header)
+
+	return &model.IdempotencyKey{
+		Key:        key,
+		StatusCode: recorder.statusCode,
+		Header:     string(encodedHeader),
+		Body:       recorder.body.String(),
+	}
+}
+
+// requestFingerprint identifies a request by its target, including its query, representation, and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("Content-Type"), r.Header.Get("If-Match"),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replay responds to a retry of a request with the recorded response.
+func replay(w http.ResponseWriter, key *model.IdempotencyKey, fingerprint string) error {
+	if key.Fingerprint != fingerprint {
+		return &app.UserError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Idempotency-Key has already been used for a different request",
+		}
+	}
+	if !key.Completed() {
+		return &app.UserError{
+			StatusCode: http.StatusConflict,
+			Message:    "a request with this Idempotency-Key is still being processed",
+		}
+	}
+
+	var header map[string]string
+	if key.Header != "" {
+		if err := json.Unmarshal([]byte(key.Header), &header); err != nil {
+			return err
+		}
+	}
+	for name, value := range header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(key.StatusCode)
+	_, err := w.Write([]byte(key.Body))
+	return err
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/jwt"
+)
+
+// jwtAuthenticator accepts bearer tokens signed by trusted keys, mapping the `sub` claim to the principal
+// along with the scopes granted by the `scope` or `scp` claim, the role given by the `role` claim or implied by
+// the scopes, and the tenant given by the `tenant` claim.
+type jwtAuthenticator struct {
+	verifier *jwt.Verifier
+}
+
+// minJWTSecretLength is the fewest bytes accepted for a JWTSecret, matching the length of an HS256 signature so that
+// the secret is no easier to guess than the signature.
+const minJWTSecretLength = 32
+
+func newJWTAuthenticator(config *Config) (*jwtAuthenticator, error) {
+	switch {
+	case config.JWTIssuer == "":
+		return nil, errors.New("AuthMethod jwt requires the JWTIssuer which tokens must name")
+	case config.JWTAudience == "":
+		return nil, errors.New("AuthMethod jwt requires the JWTAudience which tokens must name")
+	case config.JWTSecret != "" && len(config.JWTSecret) < minJWTSecretLength:
+		return nil, fmt.Errorf("JWTSecret must be at least %d bytes", minJWTSecretLength)
+	}
+
+	keys := &jwt.KeySet{}
+	if config.JWTKeysFile != "" {
+		var err error
+		if keys, err = jwt.LoadKeySet(config.JWTKeysFile); err != nil {
+			return nil, err
+		}
+	}
+	if config.JWTSecret != "" {
+		keys.AddSecret("", []byte(config.JWTSecret))
+	}
+	if len(keys.Keys) == 0 {
+		return nil, errors.New("AuthMethod jwt requires a JWTKeysFile holding signing keys or a JWTSecret")
+	}
+
+	return &jwtAuthenticator{verifier: &jwt.Verifier{
+		Keys:     keys,
+		Issuer:   config.JWTIssuer,
+		Audience: config.JWTAudience,
+		Leeway:   config.JWTLeeway,
+	}}, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(_ *app.Context, r *http.Request) (*app.Principal, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "a bearer token is required"}
+	}
+
+	claims, err := a.verifier.Verify(token, time.Now())
+	if err != nil {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "invalid bearer token: " + err.Error()}
+	}
+	if claims.Subject == "" {
+		return nil, &app.UserError{StatusCode: http.StatusUnauthorized, Message: "bearer token must have a subject"}
+	}
+
+	scopes := strings.Fields(claims.String("scope"))
+	if len(scopes) == 0 {
+		scopes = claims.Strings("scp")
+	}
+	role, ok := app.ParseRole(claims.String("role"))
+	if !ok {
+		role = app.RoleForScopes(scopes)
+	}
+	return &app.Principal{
+		ID:     "jwt:" + claims.Subject,
+		Name:   claims.String("name"),
+		Scopes: scopes,
+		Role:   role,
+		Tenant: claims.String("tenant"),
+	}, nil
+}
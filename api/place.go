@@ -1,64 +1,240 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/gorilla/mux"
 
 	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/geo"
 	"github.com/weesvc/weesvc-gorilla/model"
 )
 
-func (a *API) getPlaces(ctx *app.Context, w http.ResponseWriter, _ *http.Request) error {
-	places, err := ctx.GetPlaces()
+func (a *API) getPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	query, err := a.placeQueryFromRequest(r)
 	if err != nil {
 		return err
 	}
 
-	data, err := json.Marshal(places)
+	var page *db.PlacePage
+	params := r.URL.Query()
+	switch {
+	case params.Has("q"):
+		page, err = searchPlaces(ctx, r, query)
+	case params.Has("fuzzy"):
+		page, err = getSimilarPlaces(ctx, r, query)
+	case params.Has("near"):
+		page, err = a.getPlacesNear(ctx, r, query)
+	default:
+		page, err = ctx.GetPlaces(query)
+	}
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Write(data)
-	return err
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.Next != nil {
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, page.Next)))
+	}
+
+	return writePlaces(w, r, page.Places)
 }
 
-type createPlaceInput struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Latitude    float64 `json:"latitude"`
-	Longitude   float64 `json:"longitude"`
+// Supported modes of matching multiple tags.
+const (
+	// tagModeAll matches places having every tag
+	tagModeAll = "all"
+	// tagModeAny matches places having at least one of the tags
+	tagModeAny = "any"
+)
+
+// placeQueryFromRequest reads the `limit`, `sort`, `cursor`, `bbox`, `tag`, and `tag_mode` parameters
+// for listing places.
+func (a *API) placeQueryFromRequest(r *http.Request) (*db.PlaceQuery, error) {
+	params := r.URL.Query()
+	query := &db.PlaceQuery{}
+
+	var err error
+	if query.Limit, err = a.limitFromRequest(r, a.Config.DefaultPageSize); err != nil {
+		return nil, err
+	}
+	if query.Sort, query.Descending, err = db.ParsePlaceSort(params.Get("sort")); err != nil {
+		return nil, app.NewFieldError("sort", err.Error())
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		if query.After, err = db.DecodePlaceCursor(cursor); err != nil {
+			return nil, app.NewFieldError("cursor", err.Error())
+		}
+	}
+
+	if params.Has("bbox") {
+		box, err := geo.ParseBoundingBox(params.Get("bbox"))
+		if err != nil {
+			return nil, app.NewFieldError("bbox", err.Error())
+		}
+		query.Box = &box
+	}
+
+	query.Tags = params["tag"]
+	switch mode := params.Get("tag_mode"); mode {
+	case "", tagModeAll:
+	case tagModeAny:
+		query.AnyTag = true
+	default:
+		return nil, app.NewFieldError("tag_mode", fmt.Sprintf("must be %q or %q", tagModeAll, tagModeAny))
+	}
+
+	return query, nil
 }
 
-type createPlaceResponse struct {
-	ID uint `json:"id"`
+// limitFromRequest reads the `limit` parameter, which is capped at the largest page size.
+func (a *API) limitFromRequest(r *http.Request, defaultLimit int) (int, error) {
+	limit := defaultLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil || limit < 1 {
+			return 0, app.NewFieldError("limit", "must be a positive integer")
+		}
+	}
+	if a.Config.MaxPageSize > 0 && limit > a.Config.MaxPageSize {
+		limit = a.Config.MaxPageSize
+	}
+	return limit, nil
 }
 
-func (a *API) createPlace(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
-	var input createPlaceInput
+// getPlacesNear handles the `near` and `radius` parameters for proximity searches.
+// Results are ordered by distance, so sorting and cursors are not supported.
+func (a *API) getPlacesNear(ctx *app.Context, r *http.Request, query *db.PlaceQuery) (*db.PlacePage, error) {
+	params := r.URL.Query()
+	if params.Has("sort") || params.Has("cursor") || params.Has("bbox") || params.Has("tag") {
+		return nil, &app.ValidationError{Message: "sort, cursor, bbox, and tag are not supported with near"}
+	}
 
-	defer func() {
-		_ = r.Body.Close()
-	}()
-	body, err := io.ReadAll(r.Body)
+	center, err := geo.ParsePoint(params.Get("near"))
+	if err != nil {
+		return nil, app.NewFieldError("near", err.Error())
+	}
+	if !params.Has("radius") {
+		return nil, app.NewFieldError("radius", "is required with near")
+	}
+	radius, err := geo.ParseDistance(params.Get("radius"))
+	if err != nil {
+		return nil, app.NewFieldError("radius", err.Error())
+	}
+	if a.Config.MaxSearchRadius > 0 && radius > a.Config.MaxSearchRadius {
+		return nil, app.NewFieldError("radius", fmt.Sprintf("must be at most %gkm", a.Config.MaxSearchRadius/1000))
+	}
+
+	return ctx.GetPlacesNear(center, radius, query.Limit)
+}
+
+// searchPlaces handles the `q` parameter for text searches.
+// Results are ordered by relevance, so sorting and cursors are not supported.
+func searchPlaces(ctx *app.Context, r *http.Request, query *db.PlaceQuery) (*db.PlacePage, error) {
+	params := r.URL.Query()
+	if params.Has("sort") || params.Has("cursor") || params.Has("near") || params.Has("fuzzy") {
+		return nil, &app.ValidationError{Message: "sort, cursor, near, and fuzzy are not supported with q"}
+	}
+
+	query.Text = params.Get("q")
+	return ctx.SearchPlaces(query)
+}
+
+// getSimilarPlaces handles the `fuzzy` parameter for searches tolerating misspellings.
+// Results are ordered by similarity, so sorting and cursors are not supported.
+func getSimilarPlaces(ctx *app.Context, r *http.Request, query *db.PlaceQuery) (*db.PlacePage, error) {
+	params := r.URL.Query()
+	if params.Has("sort") || params.Has("cursor") || params.Has("bbox") || params.Has("near") || params.Has("tag") {
+		return nil, &app.ValidationError{Message: "sort, cursor, bbox, near, and tag are not supported with fuzzy"}
+	}
+
+	return ctx.GetSimilarPlaces(params.Get("fuzzy"), query.Limit)
+}
+
+// defaultSuggestionCount is the number of suggestions returned when a limit is not requested.
+const defaultSuggestionCount = 10
+
+// suggestPlaces lists places whose names complete the `prefix` parameter, for autocompletion.
+func (a *API) suggestPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := a.limitFromRequest(r, defaultSuggestionCount)
 	if err != nil {
 		return err
 	}
 
-	if err = json.Unmarshal(body, &input); err != nil {
+	places, err := ctx.SuggestPlaces(r.URL.Query().Get("prefix"), limit)
+	if err != nil {
 		return err
 	}
 
-	place := &model.Place{
-		Name:        input.Name,
-		Description: input.Description,
-		Latitude:    input.Latitude,
-		Longitude:   input.Longitude,
+	return writePlaces(w, r, places)
+}
+
+// pageURL creates the URL of the page starting at the provided cursor, retaining all other request parameters.
+func pageURL(r *http.Request, cursor *db.PlaceCursor) string {
+	params := r.URL.Query()
+	params.Set("cursor", cursor.Encode())
+	next := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+	return next.String()
+}
+
+const defaultNearestCount = 5
+
+func (a *API) getNearestPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+
+	center, err := geo.ParsePoint(params.Get("lat") + "," + params.Get("lon"))
+	if err != nil {
+		return &app.ValidationError{Message: "lat and lon: " + err.Error()}
+	}
+
+	k := defaultNearestCount
+	if params.Has("k") {
+		if k, err = strconv.Atoi(params.Get("k")); err != nil || k < 1 {
+			return app.NewFieldError("k", "must be a positive integer")
+		}
+	}
+	if a.Config.MaxPageSize > 0 && k > a.Config.MaxPageSize {
+		k = a.Config.MaxPageSize
+	}
+
+	var excludeID uint64
+	if params.Has("exclude_id") {
+		if excludeID, err = strconv.ParseUint(params.Get("exclude_id"), 10, 0); err != nil {
+			return app.NewFieldError("exclude_id", "must be a place identifier")
+		}
+	}
+
+	places, err := ctx.GetNearestPlaces(center, k, uint(excludeID), a.Config.MaxSearchRadius)
+	if err != nil {
+		return err
+	}
+
+	return writePlaces(w, r, places)
+}
+
+type createPlaceInput struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Latitude    float64  `json:"latitude"`
+	Longitude   float64  `json:"longitude"`
+	Tags        []string `json:"tags"`
+}
+
+type createPlaceResponse struct {
+	ID uint `json:"id"`
+}
+
+func (a *API) createPlace(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	place, err := placeFromCreateBody(r)
+	if err != nil {
+		return err
 	}
 
 	if err = ctx.CreatePlace(place); err != nil {
@@ -74,86 +250,177 @@ func (a *API) createPlace(ctx *app.Context, w http.ResponseWriter, r *http.Reque
 	return err
 }
 
+// createFeatureInput is a GeoJSON Feature describing a new place. Any identifier of the feature is ignored, as
+// identifiers are assigned by the service.
+type createFeatureInput struct {
+	Type       string                  `json:"type"`
+	Geometry   *model.Geometry         `json:"geometry"`
+	Properties model.FeatureProperties `json:"properties"`
+}
+
+// placeFromCreateBody reads the new place from either our JSON input or a GeoJSON Feature.
+func placeFromCreateBody(r *http.Request) (*model.Place, error) {
+	var body json.RawMessage
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(body, &object); err != nil {
+		// The body may hold a value other than an object
+		return nil, decodeError(err)
+	}
+
+	if isGeoJSON(r) || object.Type == model.GeoJSONFeature {
+		// Features may carry members beyond those of places, such as `bbox`, and identifiers which are strings,
+		// so are decoded leniently
+		var input createFeatureInput
+		if err := json.Unmarshal(body, &input); err != nil {
+			return nil, decodeError(err)
+		}
+		feature := model.Feature{Type: input.Type, Geometry: input.Geometry, Properties: input.Properties}
+		place, err := feature.Place()
+		if err != nil {
+			return nil, &app.ValidationError{Message: err.Error()}
+		}
+		return place, nil
+	}
+
+	var input createPlaceInput
+	if err := decodeJSON(bytes.NewReader(body), &input); err != nil {
+		return nil, err
+	}
+	return input.place(), nil
+}
+
+func (input *createPlaceInput) place() *model.Place {
+	return &model.Place{
+		Name:        input.Name,
+		Description: input.Description,
+		Latitude:    input.Latitude,
+		Longitude:   input.Longitude,
+		Tags:        input.Tags,
+	}
+}
+
 func (a *API) getPlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
 	id := getIDFromRequest(r)
-	place, err := ctx.GetPlaceByID(id)
-	if err != nil {
-		return handleError(w, r, err)
+	if r.URL.Query().Has("as_of") {
+		return getPlaceAsOf(ctx, w, r, id)
 	}
 
-	data, err := json.Marshal(place)
+	place, err := ctx.GetPlaceByID(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Write(data)
-	return err
+	w.Header().Set("ETag", etag(r, place))
+	w.Header().Add("Vary", "Accept")
+	if notModified(r, place) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	return writePlace(w, r, place)
 }
 
 type updatePlaceInput struct {
-	Name        *string  `json:"name"`
-	Description *string  `json:"description"`
-	Latitude    *float64 `json:"latitude"`
-	Longitude   *float64 `json:"longitude"`
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Latitude    *float64  `json:"latitude"`
+	Longitude   *float64  `json:"longitude"`
+	Tags        *[]string `json:"tags"`
+}
+
+// apply changes the place for each attribute provided in the input.
+func (input *updatePlaceInput) apply(place *model.Place) {
+	if input.Name != nil {
+		place.Name = *input.Name
+	}
+	if input.Description != nil {
+		place.Description = *input.Description
+	}
+	if input.Latitude != nil {
+		place.Latitude = *input.Latitude
+	}
+	if input.Longitude != nil {
+		place.Longitude = *input.Longitude
+	}
+	if input.Tags != nil {
+		place.Tags = *input.Tags
+	}
 }
 
 func (a *API) updatePlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
 	id := getIDFromRequest(r)
 
 	var input updatePlaceInput
+	if err := decodeBody(r, &input); err != nil {
+		return err
+	}
 
-	defer func() {
-		_ = r.Body.Close()
-	}()
-	body, err := io.ReadAll(r.Body)
+	existingPlace, err := ctx.GetPlaceByID(id)
 	if err != nil {
 		return err
 	}
-
-	if err = json.Unmarshal(body, &input); err != nil {
+	if err = checkIfMatch(r, existingPlace); err != nil {
 		return err
 	}
 
-	existingPlace, err := ctx.GetPlaceByID(id)
+	input.apply(existingPlace)
+
+	err = ctx.UpdatePlace(existingPlace)
 	if err != nil {
-		return handleError(w, r, err)
+		return err
 	}
 
-	if input.Name != nil {
-		existingPlace.Name = *input.Name
-	}
-	if input.Description != nil {
-		existingPlace.Description = *input.Description
+	w.Header().Set("ETag", etag(r, existingPlace))
+	return writePlace(w, r, existingPlace)
+}
+
+func (a *API) deletePlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	id := getIDFromRequest(r)
+	place, err := ctx.GetPlaceByID(id)
+	if err != nil {
+		return err
 	}
-	if input.Latitude != nil {
-		existingPlace.Latitude = *input.Latitude
+	if err = checkIfMatch(r, place); err != nil {
+		return err
 	}
-	if input.Longitude != nil {
-		existingPlace.Longitude = *input.Longitude
+
+	if err = ctx.DeletePlace(place); err != nil {
+		return err
 	}
 
-	err = ctx.UpdatePlace(existingPlace)
+	return writeJSON(w, map[string]string{"message": "removed"})
+}
+
+// getDeletedPlaces lists the places within the trash, most recently deleted first.
+func (a *API) getDeletedPlaces(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := a.limitFromRequest(r, a.Config.DefaultPageSize)
 	if err != nil {
 		return err
 	}
 
-	data, err := json.Marshal(existingPlace)
+	page, err := ctx.GetDeletedPlaces(limit)
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Write(data)
-	return err
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	return writePlaces(w, r, page.Places)
 }
 
-func (a *API) deletePlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
-	id := getIDFromRequest(r)
-	err := ctx.DeletePlaceByID(id)
+func (a *API) restorePlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	place, err := ctx.RestorePlace(getIDFromRequest(r))
 	if err != nil {
-		return handleError(w, r, err)
+		return err
 	}
 
-	return &app.UserError{StatusCode: http.StatusOK, Message: "removed"}
+	w.Header().Set("ETag", etag(r, place))
+	return writePlace(w, r, place)
 }
 
 func getIDFromRequest(r *http.Request) uint {
@@ -167,11 +434,3 @@ func getIDFromRequest(r *http.Request) uint {
 
 	return uint(intID)
 }
-
-func handleError(w http.ResponseWriter, r *http.Request, err error) error {
-	if strings.Contains(err.Error(), "record not found") {
-		http.NotFound(w, r)
-		return nil
-	}
-	return err
-}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+)
+
+const problemMediaType = "application/problem+json"
+
+// Problem types which further qualify the status of a response; see RFC 7807.
+const (
+	// problemTypeDefault indicates the problem has no semantics beyond the status code
+	problemTypeDefault = "about:blank"
+	// problemTypeValidation indicates the request holds invalid values, described by the `errors` of the problem
+	problemTypeValidation = "urn:weesvc:problem:validation"
+)
+
+// problem details an error response as described by RFC 7807.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// TraceID identifies the request within the service logs
+	TraceID string           `json:"trace_id,omitempty"`
+	Errors  []app.FieldError `json:"errors,omitempty"`
+}
+
+// newProblem creates the problem having the given status and default type.
+func newProblem(status int, detail string) *problem {
+	return &problem{
+		Type:   problemTypeDefault,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// problemFor describes errors caused by the request, or returns nil for any other error.
+func problemFor(err error) *problem {
+	if derr := databaseUserError(err); derr != nil {
+		err = derr
+	}
+
+	var verr *app.ValidationError
+	var uerr *app.UserError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return newProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("request body must not exceed %d bytes", maxBytesErr.Limit))
+	case errors.As(err, &verr):
+		return &problem{
+			Type:   problemTypeValidation,
+			Title:  "Invalid request",
+			Status: http.StatusBadRequest,
+			Detail: verr.Message,
+			Errors: verr.Errors,
+		}
+	case errors.As(err, &uerr):
+		return newProblem(uerr.StatusCode, uerr.Message)
+	default:
+		return nil
+	}
+}
+
+// databaseUserError describes database errors caused by the request, or returns nil for any other error.
+func databaseUserError(err error) *app.UserError {
+	switch {
+	case errors.Is(err, db.ErrNotFound):
+		return &app.UserError{StatusCode: http.StatusNotFound, Message: "not found"}
+	case errors.Is(err, db.ErrVersionMismatch):
+		return &app.UserError{StatusCode: http.StatusConflict, Message: "place has been modified"}
+	case errors.Is(err, db.ErrNotDeleted):
+		return &app.UserError{StatusCode: http.StatusConflict, Message: "place is not deleted"}
+	case errors.Is(err, db.ErrConflict):
+		return &app.UserError{StatusCode: http.StatusConflict, Message: "conflicts with an existing resource"}
+	case errors.Is(err, db.ErrConstraint):
+		return &app.UserError{StatusCode: http.StatusUnprocessableEntity, Message: "violates a data constraint"}
+	default:
+		return nil
+	}
+}
+
+// writeError responds with the problem describing an error returned by a handler.
+// Errors not caused by the request are logged and reported as an internal server error.
+func writeError(ctx *app.Context, w http.ResponseWriter, r *http.Request, err error) {
+	// A place changed concurrently fails the precondition of clients which named the version they expected
+	if errors.Is(err, db.ErrVersionMismatch) && r.Header.Get("If-Match") != "" {
+		err = &app.UserError{StatusCode: http.StatusPreconditionFailed, Message: "place has been modified"}
+	}
+	p := problemFor(err)
+	if p == nil {
+		ctx.Logger.Error(err)
+		p = newProblem(http.StatusInternalServerError, "")
+	}
+	writeProblem(ctx, w, r, p)
+}
+
+// writeProblem responds with the problem, identifying the request it occurred within.
+func writeProblem(ctx *app.Context, w http.ResponseWriter, r *http.Request, p *problem) {
+	p.Instance = r.URL.Path
+	p.TraceID = ctx.TraceID.String()
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		ctx.Logger.Error(err)
+		data = []byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`)
+		p.Status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", problemMediaType)
+	w.Header().Del("ETag")
+	w.WriteHeader(p.Status)
+	if _, err = w.Write(data); err != nil {
+		ctx.Logger.Error(err)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies reads the addresses and CIDR ranges of the proxies trusted to report the addresses of clients.
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if addr, err := netip.ParseAddr(value); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TrustedProxies entry %q; must be an address or CIDR range", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// addressForRequest provides the address of the client making the request. Requests relayed by trusted proxies are
+// attributed to the address reported by the proxies within the `Forwarded`, `X-Forwarded-For`, or `X-Real-IP`
+// header, in that order of preference, while those headers are ignored when set by any other peer.
+func (a *API) addressForRequest(r *http.Request) string {
+	peer := hostOf(r.RemoteAddr)
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !a.trustsProxy(addr) {
+		return peer
+	}
+
+	var chain []string
+	switch {
+	case r.Header.Get("Forwarded") != "":
+		chain = forwardedFor(r.Header.Values("Forwarded"))
+	case r.Header.Get("X-Forwarded-For") != "":
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			chain = append(chain, strings.Split(value, ",")...)
+		}
+	case r.Header.Get("X-Real-IP") != "":
+		chain = []string{r.Header.Get("X-Real-IP")}
+	}
+
+	// Each proxy appends the address of its peer, so the client is the nearest address not belonging to a trusted
+	// proxy; addresses further along the chain may have been forged by the client
+	client := addr
+	for i := len(chain) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hostOf(strings.TrimSpace(chain[i])))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !a.trustsProxy(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// trustsProxy reports whether the address belongs to a trusted proxy.
+func (a *API) trustsProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range a.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf provides the host of an address, which may lack a port. Addresses which are not network addresses, such
+// as those of Unix socket peers, are returned unchanged.
+func hostOf(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	if strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]") {
+		return address[1 : len(address)-1]
+	}
+	return address
+}
+
+// forwardedFor provides the `for` parameter of each element of RFC 7239 `Forwarded` headers, in order.
+func forwardedFor(values []string) []string {
+	var nodes []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				name, node, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(name, "for") {
+					nodes = append(nodes, strings.Trim(node, `"`))
+				}
+			}
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Groups of routes sharing rate limits, following the scope each route requires.
+const (
+	rateGroupRead  = "read"
+	rateGroupWrite = "write"
+	rateGroupAdmin = "admin"
+)
+
+// bucketSweepInterval is how often buckets which have refilled are forgotten, as they are no different from the
+// buckets of clients yet to make a request.
+const bucketSweepInterval = time.Minute
+
+// RateLimit bounds the requests each client may make to a group of routes.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which the token bucket of each client refills, or zero for no bucket
+	RequestsPerSecond float64
+	// Burst is the capacity of the token bucket of each client, which is one second of requests if not set
+	Burst int
+	// DailyQuota is the number of requests each client may make each day, in UTC, or zero for no quota
+	DailyQuota int
+}
+
+// capacity provides the number of requests a client may make at once after making none for a while.
+func (l RateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.RequestsPerSecond))
+}
+
+// routeGroup provides the group of the named route, given by the scope the route requires.
+func routeGroup(route string) string {
+	switch routeScopes()[route] {
+	case model.ScopePlacesRead:
+		return rateGroupRead
+	case model.ScopePlacesWrite:
+		return rateGroupWrite
+	default:
+		return rateGroupAdmin
+	}
+}
+
+// tokenBucket holds the tokens of a single client, each of which permits a request.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled, absent further requests
+	full time.Time
+}
+
+// rateDecision describes the state of a bucket after a request has been counted against it.
+type rateDecision struct {
+	allowed   bool
+	limit     int
+	remaining int
+	// reset is how long until the bucket will have refilled
+	reset time.Duration
+	// retryAfter is how long until a refused request may be retried
+	retryAfter time.Duration
+}
+
+// rateLimiter holds the token bucket of each client for each group of routes.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+	// quotaDay is the day against whose quotas requests were last counted
+	quotaDay string
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: map[string]*tokenBucket{}}
+}
+
+// take removes a token from the bucket identified by the key, refilling the bucket for the time passed since it was
+// last taken from. The request is refused when the bucket holds less than a whole token.
+func (l *rateLimiter) take(key string, limit RateLimit, now time.Time) rateDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) >= bucketSweepInterval {
+		for k, bucket := range l.buckets {
+			if !now.Before(bucket.full) {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	capacity := limit.capacity()
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updated).Seconds()*limit.RequestsPerSecond)
+	bucket.updated = now
+
+	decision := rateDecision{allowed: bucket.tokens >= 1, limit: int(capacity)}
+	if decision.allowed {
+		bucket.tokens--
+	} else {
+		decision.retryAfter = tokenDuration(1-bucket.tokens, limit.RequestsPerSecond)
+	}
+	decision.remaining = int(bucket.tokens)
+	decision.reset = tokenDuration(capacity-bucket.tokens, limit.RequestsPerSecond)
+	bucket.full = now.Add(decision.reset)
+	return decision
+}
+
+// startQuotaDay records the day against whose quotas requests are counted, reporting whether the day has changed.
+func (l *rateLimiter) startQuotaDay(day string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	changed := l.quotaDay != day
+	l.quotaDay = day
+	return changed
+}
+
+// tokenDuration provides how long a bucket refilling at the rate takes to gain the tokens.
+func tokenDuration(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
+
+// limitAddress counts the request against the token bucket of the address of the client for the group of the route,
+// refusing requests exceeding the bucket with 429. Clients are limited by address before they are authenticated.
+func (a *API) limitAddress(ctx *app.Context, w http.ResponseWriter, route string) error {
+	group, limit, ok := a.routeLimit(route)
+	if !ok || limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	return a.takeToken(w, group+" address:"+ctx.RemoteAddress, limit, time.Now())
+}
+
+// limitRate counts the request against the limits of the group of the route for the client, once authenticated.
+// Authenticated principals have a token bucket of their own, beyond that of their address, while the daily quota
+// is counted for the principal, or otherwise for the address of the client. Requests exceeding either the token
+// bucket or the daily quota of the client are refused with 429.
+func (a *API) limitRate(ctx *app.Context, w http.ResponseWriter, route string) error {
+	group, limit, ok := a.routeLimit(route)
+	if !ok {
+		return nil
+	}
+	client := "address:" + ctx.RemoteAddress
+	now := time.Now()
+	if ctx.Principal != nil {
+		client = ctx.Principal.ID
+		if limit.RequestsPerSecond > 0 {
+			if err := a.takeToken(w, group+" "+client, limit, now); err != nil {
+				return err
+			}
+		}
+	}
+	if limit.DailyQuota <= 0 {
+		return nil
+	}
+
+	day := app.QuotaDay(now)
+	if a.limiter.startQuotaDay(day) {
+		if err := ctx.ForgetQuotasBefore(day); err != nil {
+			ctx.Logger.WithError(err).Warn("unable to remove past quota counters")
+		}
+	}
+	used, err := ctx.ConsumeQuota(client, group, day)
+	if err != nil {
+		return err
+	}
+	untilTomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+	if used > limit.DailyQuota {
+		setRateLimitHeaders(w.Header(), limit.DailyQuota, 0, untilTomorrow)
+		return tooManyRequests(w, untilTomorrow)
+	}
+	if limit.RequestsPerSecond <= 0 {
+		setRateLimitHeaders(w.Header(), limit.DailyQuota, limit.DailyQuota-used, untilTomorrow)
+	}
+	return nil
+}
+
+// routeLimit provides the group of the named route and its limit, reporting false when the route is not limited.
+func (a *API) routeLimit(route string) (string, RateLimit, bool) {
+	if a.limiter == nil || route == "" {
+		return "", RateLimit{}, false
+	}
+	group := routeGroup(route)
+	limit, ok := a.Config.RateLimits[group]
+	return group, limit, ok
+}
+
+// takeToken counts the request against the token bucket identified by the key, refusing the request once the
+// bucket is empty.
+func (a *API) takeToken(w http.ResponseWriter, key string, limit RateLimit, now time.Time) error {
+	decision := a.limiter.take(key, limit, now)
+	setRateLimitHeaders(w.Header(), decision.limit, decision.remaining, decision.reset)
+	if !decision.allowed {
+		return tooManyRequests(w, decision.retryAfter)
+	}
+	return nil
+}
+
+// setRateLimitHeaders describes the limit applied to the request, per the IETF draft on RateLimit header fields.
+func setRateLimitHeaders(header http.Header, limit, remaining int, reset time.Duration) {
+	header.Set("RateLimit-Limit", strconv.Itoa(limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+}
+
+// tooManyRequests refuses the request, telling the client when it may retry.
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) error {
+	seconds := ceilSeconds(retryAfter)
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	return &app.UserError{
+		StatusCode: http.StatusTooManyRequests,
+		Message:    fmt.Sprintf("rate limit exceeded; retry in %d seconds", seconds),
+	}
+}
+
+// ceilSeconds rounds the duration up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
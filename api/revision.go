@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+type revisionResponse struct {
+	Revision  uint                          `json:"revision"`
+	Action    string                        `json:"action"`
+	Actor     string                        `json:"actor,omitempty"`
+	TraceID   string                        `json:"trace_id,omitempty"`
+	CreatedAt time.Time                     `json:"created_at"`
+	Changes   map[string]*model.FieldChange `json:"changes"`
+	Place     *model.PlaceSnapshot          `json:"place"`
+}
+
+func newRevisionResponse(revision *model.PlaceRevision) (*revisionResponse, error) {
+	changes, err := revision.DecodeChanges()
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := revision.DecodeSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &revisionResponse{
+		Revision:  revision.Revision,
+		Action:    revision.Action,
+		Actor:     revision.Actor,
+		TraceID:   revision.TraceID,
+		CreatedAt: revision.CreatedAt,
+		Changes:   changes,
+		Place:     snapshot,
+	}, nil
+}
+
+// getPlaceHistory lists every revision of the place, oldest first.
+func (a *API) getPlaceHistory(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	revisions, err := ctx.GetPlaceHistory(getIDFromRequest(r))
+	if err != nil {
+		return err
+	}
+
+	response := make([]*revisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		item, err := newRevisionResponse(revision)
+		if err != nil {
+			return err
+		}
+		response = append(response, item)
+	}
+	return writeJSON(w, response)
+}
+
+// getPlaceAsOf responds with the place as it was at the time given by the `as_of` parameter. Past states are
+// not versioned, so no entity tag is provided.
+func getPlaceAsOf(ctx *app.Context, w http.ResponseWriter, r *http.Request, id uint) error {
+	asOf, err := time.Parse(time.RFC3339Nano, r.URL.Query().Get("as_of"))
+	if err != nil {
+		return app.NewFieldError("as_of", "must be an RFC 3339 timestamp")
+	}
+
+	place, err := ctx.GetPlaceAsOf(id, asOf)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Add("Vary", "Accept")
+	return writePlace(w, r, place)
+}
+
+// revertPlaceByID changes the place back to its state following the revision given by the `to` parameter.
+func (a *API) revertPlaceByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	revision, err := strconv.ParseUint(r.URL.Query().Get("to"), 10, 0)
+	if err != nil || revision == 0 {
+		return app.NewFieldError("to", "must be a positive revision number")
+	}
+
+	place, err := ctx.GetPlaceByID(getIDFromRequest(r))
+	if err != nil {
+		return err
+	}
+	if err = checkIfMatch(r, place); err != nil {
+		return err
+	}
+
+	if err = ctx.RevertPlace(place, uint(revision)); err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", etag(r, place))
+	return writePlace(w, r, place)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+type tagInput struct {
+	Name string `json:"name"`
+}
+
+func (a *API) getTags(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	tags, err := ctx.GetTags()
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, tags)
+}
+
+func (a *API) createTag(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	var input tagInput
+	if err := decodeBody(r, &input); err != nil {
+		return err
+	}
+
+	tag := &model.Tag{Name: input.Name}
+	if err := ctx.CreateTag(tag); err != nil {
+		return err
+	}
+
+	return writeJSON(w, tag)
+}
+
+func (a *API) getTagByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	tag, err := ctx.GetTagByID(getIDFromRequest(r))
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, tag)
+}
+
+func (a *API) updateTagByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	var input tagInput
+	if err := decodeBody(r, &input); err != nil {
+		return err
+	}
+
+	tag, err := ctx.GetTagByID(getIDFromRequest(r))
+	if err != nil {
+		return err
+	}
+
+	tag.Name = input.Name
+	if err = ctx.UpdateTag(tag); err != nil {
+		return err
+	}
+
+	return writeJSON(w, tag)
+}
+
+func (a *API) deleteTagByID(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	if err := ctx.DeleteTag(getIDFromRequest(r)); err != nil {
+		return err
+	}
+
+	return writeJSON(w, map[string]string{"message": "removed"})
+}
+
+type facetsResponse struct {
+	// Total is the number of places matching the filters
+	Total int               `json:"total"`
+	Tags  []*model.TagCount `json:"tags"`
+}
+
+// getPlaceFacets counts the places matching the `bbox` and tag filters having each tag.
+func (a *API) getPlaceFacets(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	query, err := a.placeQueryFromRequest(r)
+	if err != nil {
+		return err
+	}
+
+	tags, total, err := ctx.GetTagCounts(query)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, &facetsResponse{Total: total, Tags: tags})
+}
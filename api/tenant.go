@@ -0,0 +1,51 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+type tenantInput struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (a *API) getTenants(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	tenants, err := ctx.GetTenants()
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, tenants)
+}
+
+func (a *API) createTenant(ctx *app.Context, w http.ResponseWriter, r *http.Request) error {
+	var input tenantInput
+	if err := decodeBody(r, &input); err != nil {
+		return err
+	}
+
+	tenant := &model.Tenant{ID: input.ID, Name: input.Name}
+	if err := ctx.CreateTenant(tenant); err != nil {
+		return err
+	}
+
+	return writeJSON(w, tenant)
+}
+
+// resolveTenant binds the context to the tenant named by the `X-Tenant` header, or to the tenant of the principal.
+// Without an authenticator nothing vouches for the client, so only the default tenant may be named.
+func (a *API) resolveTenant(ctx *app.Context, r *http.Request) (*app.Context, error) {
+	requested := strings.TrimSpace(r.Header.Get("X-Tenant"))
+	if a.Authenticator == nil && requested != "" && requested != model.DefaultTenant {
+		return nil, &app.UserError{
+			StatusCode: http.StatusForbidden,
+			Message:    fmt.Sprintf("tenant %q may only be accessed by authenticated clients", requested),
+		}
+	}
+	return ctx.ResolveTenant(requested)
+}
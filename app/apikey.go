@@ -0,0 +1,111 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+const (
+	// apiKeyPrefix begins every API key, distinguishing keys from other credentials
+	apiKeyPrefix = "wee_"
+	// apiKeyRandomBytes is the amount of randomness within each key
+	apiKeyRandomBytes = 32
+	// apiKeyVisibleLength is the number of leading characters of a key kept for recognizing the key
+	apiKeyVisibleLength = len(apiKeyPrefix) + 8
+	// maxAPIKeyNameLength limits the names given to keys, in characters
+	maxAPIKeyNameLength = 100
+)
+
+// apiKeyScopes lists the scopes which may be granted to API keys.
+func apiKeyScopes() []string {
+	return []string{model.ScopePlacesRead, model.ScopePlacesWrite, model.ScopeAdmin}
+}
+
+// GetAPIKeys returns every API key, including revoked keys.
+func (ctx *Context) GetAPIKeys() ([]*model.APIKey, error) {
+	return ctx.Database.GetAPIKeys()
+}
+
+// CreateAPIKey generates a new API key granting the scopes within the tenant of the context. The key itself is
+// returned alongside the stored record, which holds only its hash.
+func (ctx *Context) CreateAPIKey(name string, scopes []string) (*model.APIKey, string, error) {
+	var v validator
+	name = strings.TrimSpace(name)
+	if name == "" {
+		v.add("name", "is required")
+	} else {
+		v.text("name", name, maxAPIKeyNameLength, false)
+	}
+	if len(scopes) == 0 {
+		v.add("scopes", "are required")
+	}
+	granted := make([]string, 0, len(scopes))
+	for i, scope := range scopes {
+		switch {
+		case !slices.Contains(apiKeyScopes(), scope):
+			v.add(fmt.Sprintf("scopes[%d]", i), fmt.Sprintf("must be one of %s", strings.Join(apiKeyScopes(), ", ")))
+		case !slices.Contains(granted, scope):
+			granted = append(granted, scope)
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, "", err
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	tenant := ctx.Tenant
+	if tenant == "" {
+		tenant = model.DefaultTenant
+	}
+	key := &model.APIKey{
+		Name:     name,
+		Prefix:   secret[:apiKeyVisibleLength],
+		Hash:     hashAPIKey(secret),
+		Scopes:   strings.Join(granted, " "),
+		TenantID: tenant,
+	}
+	if err := ctx.Database.CreateAPIKey(key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// RevokeAPIKey prevents further use of the API key identified.
+func (ctx *Context) RevokeAPIKey(id uint) error {
+	return ctx.Database.RevokeAPIKey(id)
+}
+
+// AuthenticateAPIKey provides the principal granted access by the key, reporting db.ErrNotFound when the key is
+// unknown or has been revoked.
+func (ctx *Context) AuthenticateAPIKey(secret string) (*Principal, error) {
+	key, err := ctx.Database.GetAPIKeyByHash(hashAPIKey(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		ID:     fmt.Sprintf("apikey:%d", key.ID),
+		Name:   key.Name,
+		Scopes: key.ScopeList(),
+		Role:   RoleForScopes(key.ScopeList()),
+		Tenant: key.TenantID,
+	}, nil
+}
+
+// hashAPIKey digests the key for storage. Keys hold enough randomness that a fast hash suffices.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
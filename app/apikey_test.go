@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestCreateAPIKey_Validation(t *testing.T) {
+	t.Parallel()
+	ctx := &Context{}
+	_, _, err := ctx.CreateAPIKey(" ", []string{model.ScopePlacesRead, "places:delete"})
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "scopes[1]", Message: "must be one of places:read, places:write, admin"},
+		}, verr.Errors)
+	}
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	t.Parallel()
+	reader := &Principal{Scopes: []string{model.ScopePlacesRead}}
+	assert.True(t, reader.HasScope(model.ScopePlacesRead))
+	assert.False(t, reader.HasScope(model.ScopePlacesWrite))
+
+	admin := &Principal{Scopes: []string{model.ScopeAdmin}}
+	assert.True(t, admin.HasScope(model.ScopePlacesWrite))
+}
@@ -2,6 +2,11 @@
 package app
 
 import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/sirupsen/logrus"
 
 	"github.com/weesvc/weesvc-gorilla/db"
@@ -10,13 +15,57 @@ import (
 // App defines the main application state and behaviors.
 type App struct {
 	Database *db.Database
+
+	similarityOnce  sync.Once
+	similarityIndex *trigramIndex
 }
 
 // NewContext creates context to bind to an incoming request.
 func (a *App) NewContext() *Context {
 	return &Context{
-		Logger:   logrus.New(),
-		Database: a.Database,
+		Logger:     logrus.New(),
+		Database:   a.Database,
+		similarity: a.similarPlacesIndex(),
+	}
+}
+
+// similarPlacesIndex provides the index of places shared by every context, for databases lacking trigram support.
+func (a *App) similarPlacesIndex() *trigramIndex {
+	a.similarityOnce.Do(func() {
+		if a.Database != nil && !a.Database.SupportsSimilarity() {
+			a.similarityIndex = newTrigramIndex()
+		}
+	})
+	return a.similarityIndex
+}
+
+// KeepSimilarityIndexWarm builds the in-process index of similar places immediately, then rebuilds it at each
+// interval to reflect changes made by other processes, until the context is done. Databases supporting trigram
+// similarity themselves need no index, so this returns at once.
+func (a *App) KeepSimilarityIndexWarm(ctx context.Context, interval time.Duration) {
+	index := a.similarPlacesIndex()
+	if index == nil {
+		return
+	}
+
+	if err := index.build(a.Database); err != nil {
+		logrus.WithError(err).Error("unable to build similar places index")
+	}
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := index.build(a.Database); err != nil {
+				logrus.WithError(err).Error("unable to rebuild similar places index")
+			}
+		}
 	}
 }
 
@@ -45,6 +94,28 @@ func (a *App) Close() error {
 // ValidationError defines a data-centric error.
 type ValidationError struct {
 	Message string `json:"message"`
+	// Errors describes each invalid field, when attributable to specific fields
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why the value of a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// NewFieldError creates a ValidationError for a single invalid field.
+func NewFieldError(field, message string) *ValidationError {
+	return NewValidationError([]FieldError{{Field: field, Message: message}})
+}
+
+// NewValidationError creates a ValidationError summarizing each of the invalid fields.
+func NewValidationError(errors []FieldError) *ValidationError {
+	messages := make([]string, len(errors))
+	for i, e := range errors {
+		messages[i] = e.Field + ": " + e.Message
+	}
+	return &ValidationError{Message: strings.Join(messages, "; "), Errors: errors}
 }
 
 // Error creates an error message from the underlying ValidationError.
@@ -13,6 +13,16 @@ type Context struct {
 	RemoteAddress string
 	TraceID       uuid.UUID
 	Database      *db.Database
+	// Principal is the authenticated client making the request, or nil when authentication is disabled
+	Principal *Principal
+	// Tenant identifies the tenant whose places are read and changed, or is empty to span every tenant
+	Tenant string
+
+	// similarity finds similar places when the database lacks trigram support
+	similarity *trigramIndex
+	// pendingIndex holds the changes to the similarity index made within a transaction begun by Transaction, which
+	// are applied only once the transaction commits
+	pendingIndex *[]func()
 }
 
 // WithLogger associates the provided logger to the request context.
@@ -35,3 +45,37 @@ func (ctx *Context) WithTraceID(uuid uuid.UUID) *Context {
 	ret.TraceID = uuid
 	return &ret
 }
+
+// WithPrincipal associates the authenticated client to the request context, limiting the places visible through
+// the database to those the principal may view.
+func (ctx *Context) WithPrincipal(principal *Principal) *Context {
+	ret := *ctx
+	if principal.Role == "" {
+		withRole := *principal
+		withRole.Role = RoleViewer
+		principal = &withRole
+	}
+	ret.Principal = principal
+	if owner, limited := ret.Policy().visibleOwner(); limited && ret.Database != nil {
+		ret.Database = ret.Database.VisibleTo(owner)
+	}
+	return &ret
+}
+
+// WithTenant associates the tenant to the request context, limiting the places read and changed through the
+// database to those of the tenant.
+func (ctx *Context) WithTenant(tenant string) *Context {
+	ret := *ctx
+	ret.Tenant = tenant
+	if ret.Database != nil {
+		ret.Database = ret.Database.ForTenant(tenant)
+	}
+	return &ret
+}
+
+// WithDatabase associates the provided database, such as an open transaction, to the request context.
+func (ctx *Context) WithDatabase(database *db.Database) *Context {
+	ret := *ctx
+	ret.Database = database
+	return &ret
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// ReserveIdempotencyKey claims the key for a request having the given fingerprint, keeping it for the ttl.
+// When the key has already been claimed, the existing record is returned and must be checked by the caller.
+func (ctx *Context) ReserveIdempotencyKey(key, fingerprint string, ttl time.Duration) (*model.IdempotencyKey, error) {
+	now := gorm.NowFunc().UTC()
+	return ctx.Database.ReserveIdempotencyKey(&model.IdempotencyKey{
+		Key:         ctx.scopedIdempotencyKey(key),
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	})
+}
+
+// CompleteIdempotencyKey records the response to replay for retries of the request.
+func (ctx *Context) CompleteIdempotencyKey(key *model.IdempotencyKey) error {
+	scoped := *key
+	scoped.Key = ctx.scopedIdempotencyKey(key.Key)
+	return ctx.Database.CompleteIdempotencyKey(&scoped)
+}
+
+// ReleaseIdempotencyKey forgets the key, such as when the request failed and may safely be retried.
+func (ctx *Context) ReleaseIdempotencyKey(key string) error {
+	return ctx.Database.ReleaseIdempotencyKey(ctx.scopedIdempotencyKey(key))
+}
+
+// scopedIdempotencyKey qualifies the key by the tenant and the authenticated principal, if any, so that clients
+// cannot replay the responses to requests made by other clients.
+func (ctx *Context) scopedIdempotencyKey(key string) string {
+	if ctx.Tenant != "" {
+		key = ctx.Tenant + " " + key
+	}
+	if ctx.Principal != nil {
+		key = ctx.Principal.ID + " " + key
+	}
+	return key
+}
@@ -1,10 +1,179 @@
 package app
 
-import "github.com/weesvc/weesvc-gorilla/model"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
-// GetPlaces returns available places.
-func (ctx *Context) GetPlaces() ([]*model.Place, error) {
-	return ctx.Database.GetPlaces()
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/geo"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetPlaces returns a page of available places.
+func (ctx *Context) GetPlaces(query *db.PlaceQuery) (*db.PlacePage, error) {
+	if query.After != nil && !query.After.Matches(query) {
+		return nil, NewFieldError("cursor", "does not match the requested sort")
+	}
+	if err := validatePlaceFilter(query); err != nil {
+		return nil, err
+	}
+
+	page, err := ctx.Database.GetPlaces(query)
+	if err != nil {
+		return nil, err
+	}
+	return page, ctx.loadPlaceTags(page.Places...)
+}
+
+// maxSearchTextLength limits the text of searches, in characters.
+const maxSearchTextLength = 200
+
+// SearchPlaces returns the places best matching the text of the query, ordered by decreasing relevance.
+func (ctx *Context) SearchPlaces(query *db.PlaceQuery) (*db.PlacePage, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	switch {
+	case query.Text == "":
+		return nil, NewFieldError("q", "is required")
+	case utf8.RuneCountInString(query.Text) > maxSearchTextLength:
+		return nil, NewFieldError("q", fmt.Sprintf("must be at most %d characters", maxSearchTextLength))
+	}
+	if err := validatePlaceFilter(query); err != nil {
+		return nil, err
+	}
+
+	page, err := ctx.Database.SearchPlaces(query)
+	if err != nil {
+		return nil, err
+	}
+	return page, ctx.loadPlaceTags(page.Places...)
+}
+
+// exportBatchSize is the number of places whose tags are loaded together while exporting.
+const exportBatchSize = 500
+
+// ExportPlaces calls fn with each available place, along with its tags, without loading every place into memory.
+func (ctx *Context) ExportPlaces(fn func(place *model.Place) error) error {
+	batch := make([]*model.Place, 0, exportBatchSize)
+	flush := func() error {
+		if err := ctx.loadPlaceTags(batch...); err != nil {
+			return err
+		}
+		for _, place := range batch {
+			if err := fn(place); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := ctx.Database.StreamPlaces(func(place *model.Place) error {
+		if batch = append(batch, place); len(batch) < exportBatchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// GetPlacesNear returns places within radius meters of the center, ordered by increasing distance.
+// Candidates are found using a bounding box around the circle, then refined by their great-circle distance.
+func (ctx *Context) GetPlacesNear(center geo.Point, radius float64, limit int) (*db.PlacePage, error) {
+	if err := center.Validate(); err != nil {
+		return nil, NewFieldError("near", err.Error())
+	}
+	if radius <= 0 {
+		return nil, NewFieldError("radius", "must be positive")
+	}
+
+	candidates, err := ctx.Database.GetPlacesInBox(geo.BoundingBoxAround(center, radius))
+	if err != nil {
+		return nil, err
+	}
+
+	places := withinDistance(center, radius, candidates)
+	page := &db.PlacePage{Places: places, Total: len(places)}
+	if limit > 0 && len(places) > limit {
+		page.Places = places[:limit]
+	}
+	return page, ctx.loadPlaceTags(page.Places...)
+}
+
+// initialNearestRadius is the first search radius, in meters, when looking for the nearest places.
+const initialNearestRadius = 10_000
+
+// GetNearestPlaces returns the k places closest to the center, ordered by increasing distance.
+// The place identified by excludeID, if non-zero, is omitted from the results.
+//
+// The search begins within a small bounding box which doubles in size until at least k places
+// lie within its inscribed circle, allowing the coordinate index to avoid scanning the full table.
+// The search stops once the radius reaches maxRadius meters, returning fewer than k places when
+// no more lie within it. A maxRadius of zero allows the search to span the globe.
+func (ctx *Context) GetNearestPlaces(
+	center geo.Point, k int, excludeID uint, maxRadius float64,
+) ([]*model.Place, error) {
+	if err := center.Validate(); err != nil {
+		return nil, &ValidationError{Message: err.Error()}
+	}
+	if k <= 0 {
+		return nil, NewFieldError("k", "must be positive")
+	}
+
+	if globe := math.Pi * geo.EarthRadius; maxRadius <= 0 || maxRadius > globe {
+		maxRadius = globe
+	}
+	for radius := float64(initialNearestRadius); ; radius *= 2 {
+		radius = math.Min(radius, maxRadius)
+		candidates, err := ctx.Database.GetPlacesInBox(geo.BoundingBoxAround(center, radius))
+		if err != nil {
+			return nil, err
+		}
+
+		places := withinDistance(center, radius, excludePlace(candidates, excludeID))
+		if len(places) >= k || radius >= maxRadius {
+			if len(places) > k {
+				places = places[:k]
+			}
+			return places, ctx.loadPlaceTags(places...)
+		}
+	}
+}
+
+func excludePlace(places []*model.Place, id uint) []*model.Place {
+	if id == 0 {
+		return places
+	}
+	filtered := places[:0]
+	for _, place := range places {
+		if place.ID != id {
+			filtered = append(filtered, place)
+		}
+	}
+	return filtered
+}
+
+// withinDistance filters candidates to those within radius meters of the center
+// and orders them by increasing distance.
+func withinDistance(center geo.Point, radius float64, candidates []*model.Place) []*model.Place {
+	places := make([]*model.Place, 0, len(candidates))
+	for _, place := range candidates {
+		distance := geo.Distance(center, geo.Point{Latitude: place.Latitude, Longitude: place.Longitude})
+		if distance <= radius {
+			place.Distance = &distance
+			places = append(places, place)
+		}
+	}
+	sort.SliceStable(places, func(i, j int) bool {
+		return *places[i].Distance < *places[j].Distance
+	})
+	return places
 }
 
 // GetPlaceByID returns the place specified by the provided identifier.
@@ -14,43 +183,145 @@ func (ctx *Context) GetPlaceByID(id uint) (*model.Place, error) {
 		return nil, err
 	}
 
-	return place, nil
+	return place, ctx.loadPlaceTags(place)
 }
 
-// CreatePlace persists the provided place.
+// CreatePlace persists the provided place, along with its tags. The place is owned by the principal creating it,
+// if any.
 func (ctx *Context) CreatePlace(place *model.Place) error {
+	if err := ctx.authorize(ActionCreate, place); err != nil {
+		return err
+	}
+	ctx.assignOwner(place)
+	if place.Tags == nil {
+		place.Tags = []string{}
+	}
 	if err := ctx.validatePlace(place); err != nil {
 		return err
 	}
 
-	return ctx.Database.CreatePlace(place)
+	err := ctx.inTransaction(func(txCtx *Context) error {
+		if err := txCtx.Database.CreatePlace(place); err != nil {
+			return err
+		}
+		return txCtx.recordPlaceRevision(model.RevisionCreate, nil, place)
+	})
+	if err != nil {
+		return err
+	}
+	ctx.indexSimilarPlace(place)
+	return nil
 }
 
-const maxPlaceNameLength = 100
-
-func (ctx *Context) validatePlace(place *model.Place) *ValidationError {
-	if len(place.Name) > maxPlaceNameLength {
-		return &ValidationError{"name is too long"}
+// ImportPlace creates the place, or updates the existing place having the same name.
+// The returned flag reports whether a new place was created.
+func (ctx *Context) ImportPlace(place *model.Place) (created bool, err error) {
+	if verr := ctx.validatePlace(place); verr != nil {
+		return false, verr
 	}
 
-	return nil
+	err = ctx.inTransaction(func(txCtx *Context) error {
+		var before *model.PlaceSnapshot
+		existing, err := txCtx.Database.GetPlaceByName(place.Name)
+		switch {
+		case errors.Is(err, db.ErrNotFound):
+			if err = txCtx.authorize(ActionCreate, place); err != nil {
+				return err
+			}
+			txCtx.assignOwner(place)
+			if place.Tags == nil {
+				place.Tags = []string{}
+			}
+		case err != nil:
+			return err
+		default:
+			if err = txCtx.authorize(ActionUpdate, existing); err != nil {
+				return err
+			}
+			if err = txCtx.loadPlaceTags(existing); err != nil {
+				return err
+			}
+			before = model.NewPlaceSnapshot(existing)
+		}
+
+		if created, err = txCtx.Database.UpsertPlace(place); err != nil {
+			return err
+		}
+		if created {
+			return txCtx.recordPlaceRevision(model.RevisionCreate, nil, place)
+		}
+		if place.Tags == nil {
+			place.Tags = before.Tags
+		}
+		return txCtx.recordPlaceRevision(model.RevisionUpdate, before, place)
+	})
+	if err != nil {
+		return false, err
+	}
+	ctx.indexSimilarPlace(place)
+	return created, nil
 }
 
-// UpdatePlace saves changes made to the provided place.
+// UpdatePlace saves changes made to the provided place, replacing its tags unless nil.
 func (ctx *Context) UpdatePlace(place *model.Place) error {
+	return ctx.updatePlace(place, model.RevisionUpdate)
+}
+
+// updatePlace saves changes made to the place, recording them as a revision having the given action.
+func (ctx *Context) updatePlace(place *model.Place, action string) error {
 	if err := ctx.validatePlace(place); err != nil {
 		return err
 	}
 
-	return ctx.Database.UpdatePlace(place)
+	err := ctx.inTransaction(func(txCtx *Context) error {
+		before, err := txCtx.GetPlaceByID(place.ID)
+		if err != nil {
+			return err
+		}
+		if err = txCtx.authorize(ActionUpdate, before); err != nil {
+			return err
+		}
+		if err = txCtx.Database.UpdatePlace(place); err != nil {
+			return err
+		}
+		if place.Tags == nil {
+			place.Tags = before.Tags
+		}
+		return txCtx.recordPlaceRevision(action, model.NewPlaceSnapshot(before), place)
+	})
+	if err != nil {
+		return err
+	}
+	ctx.indexSimilarPlace(place)
+	return nil
 }
 
 // DeletePlaceByID removes the place from storage given the identifier.
 func (ctx *Context) DeletePlaceByID(id uint) error {
-	_, err := ctx.GetPlaceByID(id)
+	place, err := ctx.GetPlaceByID(id)
 	if err != nil {
 		return err
 	}
 
-	return ctx.Database.DeletePlaceByID(id)
+	return ctx.DeletePlace(place)
+}
+
+// DeletePlace removes the place from storage, provided it has not been modified since it was read.
+func (ctx *Context) DeletePlace(place *model.Place) error {
+	if err := ctx.authorize(ActionDelete, place); err != nil {
+		return err
+	}
+
+	err := ctx.inTransaction(func(txCtx *Context) error {
+		if err := txCtx.Database.DeletePlace(place); err != nil {
+			return err
+		}
+		snapshot := model.NewPlaceSnapshot(place)
+		return txCtx.recordPlaceRevision(model.RevisionDelete, snapshot, place)
+	})
+	if err != nil {
+		return err
+	}
+	ctx.unindexSimilarPlace(place.ID)
+	return nil
 }
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Role determines what a principal may do with places.
+type Role string
+
+// Roles of principals, each permitting more than the last.
+const (
+	// RoleViewer may view the places which are shared, having no owner, and the places they own
+	RoleViewer Role = "viewer"
+	// RoleEditor may view every place, create places, which they then own, and change the places they own or
+	// which are shared
+	RoleEditor Role = "editor"
+	// RoleAdmin may do anything
+	RoleAdmin Role = "admin"
+)
+
+// ParseRole reads the name of a role, reporting whether the name is known.
+func ParseRole(name string) (Role, bool) {
+	role := Role(name)
+	return role, slices.Contains([]Role{RoleViewer, RoleEditor, RoleAdmin}, role)
+}
+
+// RoleForScopes provides the role implied by the scopes granted to a principal.
+func RoleForScopes(scopes []string) Role {
+	switch {
+	case slices.Contains(scopes, model.ScopeAdmin):
+		return RoleAdmin
+	case slices.Contains(scopes, model.ScopePlacesWrite):
+		return RoleEditor
+	default:
+		return RoleViewer
+	}
+}
+
+// Action is something a principal may do with a place.
+type Action string
+
+// Actions governed by the policy.
+const (
+	ActionView   Action = "view"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Policy decides what the principal may do with places. Without a principal, as when authentication is disabled or
+// for commands, every action is allowed.
+type Policy struct {
+	principal *Principal
+}
+
+// Allows reports whether the principal may take the action on the place.
+func (p Policy) Allows(action Action, place *model.Place) bool {
+	if p.principal == nil {
+		return true
+	}
+
+	switch p.principal.Role {
+	case RoleAdmin:
+		return true
+	case RoleEditor:
+		return action == ActionView || action == ActionCreate || p.sharedOrOwned(place)
+	default:
+		return action == ActionView && p.sharedOrOwned(place)
+	}
+}
+
+// visibleOwner provides the owner whose places are visible alongside shared places, reporting false when every place
+// is visible.
+func (p Policy) visibleOwner() (string, bool) {
+	if p.principal == nil || p.principal.Role == RoleAdmin || p.principal.Role == RoleEditor {
+		return "", false
+	}
+	return p.principal.ID, true
+}
+
+func (p Policy) sharedOrOwned(place *model.Place) bool {
+	return place.OwnerID == nil || *place.OwnerID == p.principal.ID
+}
+
+// Policy provides the policy governing the principal of the context.
+func (ctx *Context) Policy() Policy {
+	return Policy{principal: ctx.Principal}
+}
+
+// authorize ensures the principal of the context may take the action on the place.
+func (ctx *Context) authorize(action Action, place *model.Place) error {
+	if ctx.Policy().Allows(action, place) {
+		return nil
+	}
+
+	message := fmt.Sprintf("the %s role may not %s this place", ctx.Principal.Role, action)
+	if action == ActionCreate {
+		message = fmt.Sprintf("the %s role may not create places", ctx.Principal.Role)
+	}
+	return &UserError{StatusCode: http.StatusForbidden, Message: message}
+}
+
+// assignOwner records the principal of the context, if any, as the owner of the new place.
+func (ctx *Context) assignOwner(place *model.Place) {
+	if ctx.Principal != nil {
+		owner := ctx.Principal.ID
+		place.OwnerID = &owner
+	}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	t.Parallel()
+	owner, other := "apikey:1", "apikey:2"
+	shared := &model.Place{}
+	owned := &model.Place{OwnerID: &owner}
+	othersPlace := &model.Place{OwnerID: &other}
+
+	testCases := []struct {
+		role    Role
+		action  Action
+		place   *model.Place
+		allowed bool
+	}{
+		{RoleViewer, ActionView, shared, true},
+		{RoleViewer, ActionView, owned, true},
+		{RoleViewer, ActionView, othersPlace, false},
+		{RoleViewer, ActionCreate, shared, false},
+		{RoleViewer, ActionUpdate, owned, false},
+		{RoleEditor, ActionView, othersPlace, true},
+		{RoleEditor, ActionCreate, shared, true},
+		{RoleEditor, ActionUpdate, shared, true},
+		{RoleEditor, ActionUpdate, owned, true},
+		{RoleEditor, ActionDelete, othersPlace, false},
+		{RoleAdmin, ActionDelete, othersPlace, true},
+	}
+	for _, tc := range testCases {
+		policy := Policy{principal: &Principal{ID: owner, Role: tc.role}}
+		assert.Equal(t, tc.allowed, policy.Allows(tc.action, tc.place), "%s %s %+v", tc.role, tc.action, tc.place)
+	}
+	assert.True(t, Policy{}.Allows(ActionDelete, othersPlace), "expected every action without a principal")
+}
+
+func TestRoleForScopes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, RoleViewer, RoleForScopes([]string{model.ScopePlacesRead}))
+	assert.Equal(t, RoleEditor, RoleForScopes([]string{model.ScopePlacesRead, model.ScopePlacesWrite}))
+	assert.Equal(t, RoleAdmin, RoleForScopes([]string{model.ScopeAdmin}))
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"slices"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Principal identifies the client on whose behalf a request is made, along with what the client may do.
+type Principal struct {
+	// ID identifies the principal across requests, such as `apikey:3`
+	ID     string
+	Name   string
+	Scopes []string
+	// Role determines which places the principal may view and change; principals lacking a role are viewers
+	Role Role
+	// Tenant identifies the only tenant whose places the principal may access, or is empty for the default tenant
+	Tenant string
+}
+
+// HasScope reports whether the principal is granted the scope. The admin scope grants every scope.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, model.ScopeAdmin)
+}
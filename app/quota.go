@@ -0,0 +1,24 @@
+package app
+
+import (
+	"time"
+)
+
+// quotaDayLayout formats the days over which quotas are counted.
+const quotaDayLayout = "2006-01-02"
+
+// QuotaDay provides the day, in UTC, against whose quotas requests made at the given time are counted.
+func QuotaDay(now time.Time) string {
+	return now.UTC().Format(quotaDayLayout)
+}
+
+// ConsumeQuota counts a request made by the client to the group of routes against the quota of the day,
+// returning the number of requests counted for the day, including this one.
+func (ctx *Context) ConsumeQuota(client, routeGroup, day string) (int, error) {
+	return ctx.Database.IncrementQuotaCounter(client, routeGroup, day)
+}
+
+// ForgetQuotasBefore removes the counts of requests made before the given day, which no longer affect any quota.
+func (ctx *Context) ForgetQuotasBefore(day string) error {
+	return ctx.Database.DeleteQuotaCountersBefore(day)
+}
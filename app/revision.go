@@ -0,0 +1,165 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// errPlaceDidNotExist is reported when viewing a place at a time before it was created or after it was deleted.
+func errPlaceDidNotExist() error {
+	return &UserError{StatusCode: http.StatusNotFound, Message: "place did not exist at the requested time"}
+}
+
+// GetPlaceHistory returns every revision of the place identified, in order of revision.
+// Places created before revisions were recorded have no history.
+func (ctx *Context) GetPlaceHistory(id uint) ([]*model.PlaceRevision, error) {
+	if _, err := ctx.Database.GetPlaceByIDUnscoped(id); err != nil {
+		return nil, err
+	}
+	return ctx.Database.GetPlaceRevisions(id)
+}
+
+// GetPlaceAsOf returns the place identified as it was at the given time, reconstructed from its revisions.
+func (ctx *Context) GetPlaceAsOf(id uint, asOf time.Time) (*model.Place, error) {
+	place, err := ctx.Database.GetPlaceByIDUnscoped(id)
+	if err != nil {
+		return nil, err
+	}
+	if place.CreatedAt.After(asOf) {
+		return nil, errPlaceDidNotExist()
+	}
+	revisions, err := ctx.Database.GetPlaceRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *model.PlaceRevision
+	for _, revision := range revisions {
+		if !revision.CreatedAt.After(asOf) {
+			latest = revision
+		}
+	}
+
+	var snapshot *model.PlaceSnapshot
+	switch {
+	case latest != nil && latest.Action == model.RevisionDelete:
+		return nil, errPlaceDidNotExist()
+	case latest != nil:
+		snapshot, err = latest.DecodeSnapshot()
+		place.UpdatedAt = latest.CreatedAt
+	case len(revisions) == 0:
+		// without history, the place is assumed unchanged since it was created
+		if place.DeletedAt != nil && !place.DeletedAt.After(asOf) {
+			return nil, errPlaceDidNotExist()
+		}
+		place.DeletedAt = nil
+		return place, ctx.loadPlaceTags(place)
+	case revisions[0].Action == model.RevisionCreate:
+		return nil, errPlaceDidNotExist()
+	default:
+		// the place predates its history, so its state is that preceding its first revision
+		snapshot, err = revisions[0].DecodePreviousSnapshot()
+		place.UpdatedAt = place.CreatedAt
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Apply(place)
+	place.DeletedAt = nil
+	return place, nil
+}
+
+// RevertPlace changes the place back to its state following the given revision, recording the change as a new
+// revision.
+func (ctx *Context) RevertPlace(place *model.Place, revision uint) error {
+	target, err := ctx.Database.GetPlaceRevision(place.ID, revision)
+	if errors.Is(err, db.ErrNotFound) {
+		return NewFieldError("to", fmt.Sprintf("revision %d of place %d does not exist", revision, place.ID))
+	}
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := target.DecodeSnapshot()
+	if err != nil {
+		return err
+	}
+	snapshot.Apply(place)
+	return ctx.updatePlace(place, model.RevisionRevert)
+}
+
+// inTransaction calls fn with a context bound to a database transaction, so that each change to a place is
+// recorded along with its revision, or not at all.
+func (ctx *Context) inTransaction(fn func(txCtx *Context) error) error {
+	return ctx.Database.Transaction(func(tx *db.Database) error {
+		return fn(ctx.WithDatabase(tx))
+	})
+}
+
+// Transaction calls fn with a context bound to a database transaction spanning several changes, as for batches.
+// Changes to the in-process index of similar places are held until the transaction commits, so that a rollback
+// leaves the index matching the database.
+func (ctx *Context) Transaction(fn func(txCtx *Context) error) error {
+	if ctx.pendingIndex != nil {
+		// The changes join the enclosing transaction, which applies them upon committing
+		return ctx.inTransaction(fn)
+	}
+
+	var pending []func()
+	err := ctx.Database.Transaction(func(tx *db.Database) error {
+		txCtx := ctx.WithDatabase(tx)
+		txCtx.pendingIndex = &pending
+		return fn(txCtx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, change := range pending {
+		change()
+	}
+	return nil
+}
+
+// actor identifies who is making changes within the context: the authenticated principal, if any, or otherwise the
+// address of the client.
+func (ctx *Context) actor() string {
+	if ctx.Principal != nil {
+		return ctx.Principal.ID
+	}
+	return ctx.RemoteAddress
+}
+
+// recordPlaceRevision records the change made to the place, given its state before the change; before is nil when
+// the place was created.
+func (ctx *Context) recordPlaceRevision(action string, before *model.PlaceSnapshot, place *model.Place) error {
+	after := model.NewPlaceSnapshot(place)
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+	changes, err := json.Marshal(before.Diff(after))
+	if err != nil {
+		return err
+	}
+
+	revision := &model.PlaceRevision{
+		PlaceID:  place.ID,
+		Action:   action,
+		Snapshot: string(snapshot),
+		Changes:  string(changes),
+		Actor:    ctx.actor(),
+	}
+	if ctx.TraceID != uuid.Nil {
+		revision.TraceID = ctx.TraceID.String()
+	}
+	return ctx.Database.CreatePlaceRevision(revision)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetSimilarPlaces returns the places whose name or description resemble the text despite misspellings,
+// ordered by decreasing similarity.
+func (ctx *Context) GetSimilarPlaces(text string, limit int) (*db.PlacePage, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case text == "":
+		return nil, NewFieldError("fuzzy", "is required")
+	case utf8.RuneCountInString(text) > maxSearchTextLength:
+		return nil, NewFieldError("fuzzy", fmt.Sprintf("must be at most %d characters", maxSearchTextLength))
+	}
+
+	var page *db.PlacePage
+	var err error
+	if ctx.similarity == nil {
+		page, err = ctx.Database.GetSimilarPlaces(text, limit)
+	} else {
+		page, err = ctx.getSimilarPlacesInProcess(text, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page, ctx.loadPlaceTags(page.Places...)
+}
+
+// getSimilarPlacesInProcess finds candidates using the in-process index, then scores the current state of each
+// candidate so that places changed since they were indexed are never reported by their former values.
+func (ctx *Context) getSimilarPlacesInProcess(text string, limit int) (*db.PlacePage, error) {
+	// The index holds the places of every tenant, while only those visible through the database are fetched
+	if err := ctx.similarity.ensureBuilt(ctx.Database.ForTenant("").VisibleTo("")); err != nil {
+		return nil, err
+	}
+
+	candidates, err := ctx.Database.GetPlacesByIDs(ctx.similarity.search(text))
+	if err != nil {
+		return nil, err
+	}
+
+	query := trigrams(text)
+	places := candidates[:0]
+	for _, place := range candidates {
+		score, matched := placeSimilarity(query, trigrams(place.Name), trigrams(place.Description))
+		if matched {
+			place.Similarity = &score
+			places = append(places, place)
+		}
+	}
+	sort.SliceStable(places, func(i, j int) bool {
+		return *places[i].Similarity > *places[j].Similarity
+	})
+
+	page := &db.PlacePage{Places: places, Total: len(places)}
+	if limit > 0 && len(places) > limit {
+		page.Places = places[:limit]
+	}
+	return page, nil
+}
+
+// SuggestPlaces returns places having a name, or a word within their name, beginning with the prefix.
+func (ctx *Context) SuggestPlaces(prefix string, limit int) ([]*model.Place, error) {
+	prefix = strings.TrimSpace(prefix)
+	switch {
+	case prefix == "":
+		return nil, NewFieldError("prefix", "is required")
+	case utf8.RuneCountInString(prefix) > maxPlaceNameLength:
+		return nil, NewFieldError("prefix", fmt.Sprintf("must be at most %d characters", maxPlaceNameLength))
+	}
+
+	places, err := ctx.Database.SuggestPlaces(prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return places, ctx.loadPlaceTags(places...)
+}
+
+// indexSimilarPlace reflects the created or updated place within the in-process index, if any.
+func (ctx *Context) indexSimilarPlace(place *model.Place) {
+	if ctx.similarity != nil {
+		indexed := *place
+		ctx.changeSimilarityIndex(func() {
+			ctx.similarity.put(&indexed)
+		})
+	}
+}
+
+// unindexSimilarPlace removes the deleted place from the in-process index, if any.
+func (ctx *Context) unindexSimilarPlace(id uint) {
+	if ctx.similarity != nil {
+		ctx.changeSimilarityIndex(func() {
+			ctx.similarity.remove(id)
+		})
+	}
+}
+
+// changeSimilarityIndex applies the change to the in-process index at once, or holds it until the transaction of
+// the context commits.
+func (ctx *Context) changeSimilarityIndex(change func()) {
+	if ctx.pendingIndex != nil {
+		*ctx.pendingIndex = append(*ctx.pendingIndex, change)
+		return
+	}
+	change()
+}
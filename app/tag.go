@@ -0,0 +1,174 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Limits on the tags of a place.
+const (
+	maxTagNameLength = 50
+	maxPlaceTags     = 20
+)
+
+// GetTags returns every tag, ordered by name.
+func (ctx *Context) GetTags() ([]*model.Tag, error) {
+	return ctx.Database.GetTags()
+}
+
+// GetTagByID returns the tag specified by the provided identifier.
+func (ctx *Context) GetTagByID(id uint) (*model.Tag, error) {
+	return ctx.Database.GetTagByID(id)
+}
+
+// CreateTag persists the provided tag.
+func (ctx *Context) CreateTag(tag *model.Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	return ctx.Database.CreateTag(tag)
+}
+
+// UpdateTag renames the provided tag, which is applied to the same places as before.
+func (ctx *Context) UpdateTag(tag *model.Tag) error {
+	if err := validateTag(tag); err != nil {
+		return err
+	}
+
+	return ctx.changeTag(tag.ID, func(txCtx *Context) error {
+		return txCtx.Database.UpdateTag(tag)
+	})
+}
+
+// DeleteTag removes the tag from storage, and from every place it was applied to.
+func (ctx *Context) DeleteTag(id uint) error {
+	return ctx.changeTag(id, func(txCtx *Context) error {
+		return txCtx.Database.DeleteTag(id)
+	})
+}
+
+// changeTag makes the change to the tag within a transaction, along with updating each place having the tag and
+// recording the change to its tags as a revision.
+func (ctx *Context) changeTag(id uint, change func(txCtx *Context) error) error {
+	return ctx.inTransaction(func(txCtx *Context) error {
+		places, err := txCtx.Database.GetTaggedPlaces(id)
+		if err != nil {
+			return err
+		}
+		if err = txCtx.loadPlaceTags(places...); err != nil {
+			return err
+		}
+		before := make([]*model.PlaceSnapshot, len(places))
+		for i, place := range places {
+			before[i] = model.NewPlaceSnapshot(place)
+		}
+
+		if err = change(txCtx); err != nil {
+			return err
+		}
+		if err = txCtx.Database.TouchPlaces(places); err != nil {
+			return err
+		}
+		if err = txCtx.loadPlaceTags(places...); err != nil {
+			return err
+		}
+		for i, place := range places {
+			if err = txCtx.recordPlaceRevision(model.RevisionUpdate, before[i], place); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetTagCounts returns the number of places matching the query having each tag,
+// along with the total number of places matching the query.
+func (ctx *Context) GetTagCounts(query *db.PlaceQuery) ([]*model.TagCount, int, error) {
+	if err := validatePlaceFilter(query); err != nil {
+		return nil, 0, err
+	}
+
+	return ctx.Database.GetTagCounts(query)
+}
+
+// loadPlaceTags provides the tags of each of the places returned to callers.
+func (ctx *Context) loadPlaceTags(places ...*model.Place) error {
+	return ctx.Database.LoadPlaceTags(places)
+}
+
+// validateTag checks the name of the tag, which is stored in lowercase without surrounding whitespace.
+func validateTag(tag *model.Tag) *ValidationError {
+	var v validator
+	tag.Name = normalizeTagName(tag.Name)
+	v.tagName("name", tag.Name)
+	return v.err()
+}
+
+// validatePlaceFilter checks the bounding box and tags restricting which places are matched by the query.
+func validatePlaceFilter(query *db.PlaceQuery) *ValidationError {
+	var v validator
+	if query.Box != nil {
+		if err := query.Box.Validate(); err != nil {
+			v.add("bbox", err.Error())
+		}
+	}
+	query.Tags = normalizeTagNames(query.Tags)
+	for _, name := range query.Tags {
+		v.tagName("tag", name)
+	}
+	return v.err()
+}
+
+// tagName checks the name of a tag consists of letters, numbers, spaces, and the punctuation `-`, `_`, and `.`.
+func (v *validator) tagName(field, name string) {
+	switch {
+	case name == "":
+		v.add(field, "is required")
+	case utf8.RuneCountInString(name) > maxTagNameLength:
+		v.add(field, fmt.Sprintf("must be at most %d characters", maxTagNameLength))
+	case strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !strings.ContainsRune(" -_.", r)
+	}) >= 0:
+		v.add(field, "must contain only letters, numbers, spaces, and the characters '-', '_', and '.'")
+	}
+}
+
+// tags checks the tags of a place, which are normalized and stripped of duplicates.
+func (v *validator) tags(field string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	for i, name := range names {
+		v.tagName(fmt.Sprintf("%s[%d]", field, i), normalizeTagName(name))
+	}
+	names = normalizeTagNames(names)
+	if len(names) > maxPlaceTags {
+		v.add(field, fmt.Sprintf("at most %d are allowed", maxPlaceTags))
+	}
+	return names
+}
+
+// normalizeTagName matches tags regardless of case or surrounding whitespace.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// normalizeTagNames normalizes each name, omitting duplicates.
+func normalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = normalizeTagName(name)
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorTags(t *testing.T) {
+	t.Parallel()
+	var v validator
+	assert.Nil(t, v.tags("tags", nil))
+	assert.Equal(t, []string{"airport", "national park"}, v.tags("tags", []string{" Airport", "airport", "National Park"}))
+	assert.Nil(t, v.err())
+
+	v.tags("tags", []string{"ok", "", "no,commas"})
+	if err := v.err(); assert.NotNil(t, err) {
+		assert.Equal(t, []FieldError{
+			{Field: "tags[1]", Message: "is required"},
+			{Field: "tags[2]", Message: "must contain only letters, numbers, spaces, and the characters '-', '_', and '.'"},
+		}, err.Errors)
+	}
+
+	v = validator{}
+	names := make([]string, maxPlaceTags+1)
+	for i := range names {
+		names[i] = string(rune('a' + i))
+	}
+	v.tags("tags", names)
+	if err := v.err(); assert.NotNil(t, err) {
+		assert.Equal(t, "tags: at most 20 are allowed", err.Message)
+	}
+}
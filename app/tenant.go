@@ -0,0 +1,104 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Limits on the values of a tenant.
+const (
+	maxTenantIDLength   = 63
+	maxTenantNameLength = 100
+)
+
+// GetTenants returns every tenant, ordered by identifier.
+func (ctx *Context) GetTenants() ([]*model.Tenant, error) {
+	if err := ctx.authorizeTenants(); err != nil {
+		return nil, err
+	}
+	return ctx.Database.GetTenants()
+}
+
+// CreateTenant persists the provided tenant, which has no places until they are created within it.
+func (ctx *Context) CreateTenant(tenant *model.Tenant) error {
+	if err := ctx.authorizeTenants(); err != nil {
+		return err
+	}
+
+	var v validator
+	tenant.ID = strings.TrimSpace(tenant.ID)
+	switch {
+	case tenant.ID == "":
+		v.add("id", "is required")
+	case len(tenant.ID) > maxTenantIDLength:
+		v.add("id", fmt.Sprintf("must be at most %d characters", maxTenantIDLength))
+	case strings.HasPrefix(tenant.ID, "-") || strings.IndexFunc(tenant.ID, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-'
+	}) >= 0:
+		v.add("id", "must hold only lowercase letters, digits, and hyphens, beginning with a letter or digit")
+	}
+	tenant.Name = strings.TrimSpace(tenant.Name)
+	if tenant.Name == "" {
+		v.add("name", "is required")
+	} else {
+		v.text("name", tenant.Name, maxTenantNameLength, false)
+	}
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	return ctx.Database.CreateTenant(tenant)
+}
+
+// authorizeTenants ensures the principal of the context may manage tenants, which requires an administrator of the
+// default tenant. Administrators of other tenants manage only their own tenant, so may not see or create others.
+func (ctx *Context) authorizeTenants() error {
+	if ctx.Principal == nil {
+		return nil
+	}
+	if ctx.Principal.Role != RoleAdmin || (ctx.Principal.Tenant != "" && ctx.Principal.Tenant != model.DefaultTenant) {
+		return &UserError{
+			StatusCode: http.StatusForbidden,
+			Message:    "tenants may only be managed by administrators of the default tenant",
+		}
+	}
+	return nil
+}
+
+// ResolveTenant binds the context to the tenant requested by the client, which defaults to the tenant of the
+// principal, if any, or otherwise to the default tenant. Principals may only request their own tenant.
+func (ctx *Context) ResolveTenant(requested string) (*Context, error) {
+	tenant := requested
+	if ctx.Principal != nil {
+		tenant = ctx.Principal.Tenant
+		if tenant == "" {
+			tenant = model.DefaultTenant
+		}
+		if requested != "" && requested != tenant {
+			return nil, &UserError{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("the principal may not access tenant %q", requested),
+			}
+		}
+	}
+	if tenant == "" {
+		tenant = model.DefaultTenant
+	}
+
+	// The default tenant is created along with the tenants table, so is known to exist
+	if tenant != model.DefaultTenant {
+		_, err := ctx.Database.GetTenantByID(tenant)
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, &UserError{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("unknown tenant %q", tenant)}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ctx.WithTenant(tenant), nil
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestCreateTenant_Validation(t *testing.T) {
+	t.Parallel()
+	ctx := &Context{}
+	err := ctx.CreateTenant(&model.Tenant{ID: "-Acme", Name: " "})
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []FieldError{
+			{Field: "id", Message: "must hold only lowercase letters, digits, and hyphens, beginning with a letter or digit"},
+			{Field: "name", Message: "is required"},
+		}, verr.Errors)
+	}
+}
+
+func TestTenants_Authorization(t *testing.T) {
+	t.Parallel()
+	for _, principal := range []*Principal{
+		{ID: "apikey:1", Role: RoleAdmin, Tenant: "acme"},
+		{ID: "apikey:2", Role: RoleEditor, Tenant: model.DefaultTenant},
+	} {
+		ctx := &Context{Principal: principal}
+		var uerr *UserError
+		_, err := ctx.GetTenants()
+		if assert.ErrorAs(t, err, &uerr) {
+			assert.Equal(t, http.StatusForbidden, uerr.StatusCode)
+		}
+		if assert.ErrorAs(t, ctx.CreateTenant(&model.Tenant{ID: "globex", Name: "Globex"}), &uerr) {
+			assert.Equal(t, http.StatusForbidden, uerr.StatusCode)
+		}
+	}
+
+	// Administrators of the default tenant are only refused the invalid tenant
+	ctx := &Context{Principal: &Principal{ID: "apikey:3", Role: RoleAdmin}}
+	var verr *ValidationError
+	assert.ErrorAs(t, ctx.CreateTenant(&model.Tenant{ID: "-Acme", Name: "Acme"}), &verr)
+}
+
+func TestResolveTenant(t *testing.T) {
+	t.Parallel()
+	open, err := (&Context{}).ResolveTenant("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.DefaultTenant, open.Tenant)
+	}
+
+	principal := &Context{Principal: &Principal{ID: "apikey:1", Tenant: model.DefaultTenant}}
+	resolved, err := principal.ResolveTenant(model.DefaultTenant)
+	if assert.NoError(t, err) {
+		assert.Equal(t, model.DefaultTenant, resolved.Tenant)
+	}
+
+	_, err = principal.ResolveTenant("acme")
+	var uerr *UserError
+	if assert.ErrorAs(t, err, &uerr) {
+		assert.Equal(t, http.StatusForbidden, uerr.StatusCode)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetDeletedPlaces returns the places within the trash, most recently deleted first.
+func (ctx *Context) GetDeletedPlaces(limit int) (*db.PlacePage, error) {
+	page, err := ctx.Database.GetDeletedPlaces(limit)
+	if err != nil {
+		return nil, err
+	}
+	return page, ctx.loadPlaceTags(page.Places...)
+}
+
+// RestorePlace returns the place identified from the trash, along with its tags.
+func (ctx *Context) RestorePlace(id uint) (*model.Place, error) {
+	var place *model.Place
+	err := ctx.inTransaction(func(txCtx *Context) error {
+		deleted, err := txCtx.Database.GetPlaceByIDUnscoped(id)
+		if err != nil {
+			return err
+		}
+		if err = txCtx.authorize(ActionDelete, deleted); err != nil {
+			return err
+		}
+		if place, err = txCtx.Database.RestorePlace(id); err != nil {
+			return err
+		}
+		if err = txCtx.loadPlaceTags(place); err != nil {
+			return err
+		}
+		snapshot := model.NewPlaceSnapshot(place)
+		return txCtx.recordPlaceRevision(model.RevisionRestore, snapshot, place)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.indexSimilarPlace(place)
+	return place, nil
+}
+
+// PurgePlaces permanently removes the places which have been within the trash for longer than the given age,
+// returning the number of places removed.
+func (ctx *Context) PurgePlaces(olderThan time.Duration) (int64, error) {
+	if olderThan < 0 {
+		return 0, NewFieldError("older-than", "must not be negative")
+	}
+
+	return ctx.Database.PurgePlaces(gorm.NowFunc().Add(-olderThan))
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// trigramSet holds the distinct trigrams of some text.
+type trigramSet map[string]struct{}
+
+// trigrams extracts the trigrams of each word of the text, ignoring case. As with the Postgres pg_trgm
+// extension, words are padded with two spaces before and one after, so that "dam" yields
+// "  d", " da", "dam", and "am ".
+func trigrams(text string) trigramSet {
+	set := trigramSet{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// similarity is the proportion of trigrams shared by both sets.
+func (s trigramSet) similarity(other trigramSet) float64 {
+	shared := s.shared(other)
+	if union := len(s) + len(other) - shared; union > 0 {
+		return float64(shared) / float64(union)
+	}
+	return 0
+}
+
+// coverage is the proportion of the trigrams of the set found within the other set, approximating
+// the similarity of the set to the most similar words of the other.
+func (s trigramSet) coverage(other trigramSet) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+	return float64(s.shared(other)) / float64(len(s))
+}
+
+func (s trigramSet) shared(other trigramSet) int {
+	count := 0
+	for trigram := range s {
+		if _, ok := other[trigram]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+// placeSimilarity scores the similarity of the place to the trigrams of the text, reporting whether the
+// place is similar enough to be matched.
+func placeSimilarity(text trigramSet, name, description trigramSet) (score float64, matched bool) {
+	nameScore := text.similarity(name)
+	descriptionScore := text.coverage(description)
+	matched = nameScore >= db.NameSimilarityThreshold || descriptionScore >= db.DescriptionSimilarityThreshold
+	if descriptionScore > nameScore {
+		return descriptionScore, matched
+	}
+	return nameScore, matched
+}
+
+type trigramEntry struct {
+	name        trigramSet
+	description trigramSet
+}
+
+func newTrigramEntry(place *model.Place) *trigramEntry {
+	return &trigramEntry{name: trigrams(place.Name), description: trigrams(place.Description)}
+}
+
+// trigramIndex finds places similar to some text for databases lacking trigram support, mapping each
+// trigram to the places containing it so that only places sharing a trigram with the text are scored.
+//
+// The index is built from every place when first needed, then updated as places are changed through the
+// application. Changes made elsewhere, such as by other processes, are reflected when the index is rebuilt.
+type trigramIndex struct {
+	mu       sync.RWMutex
+	built    bool
+	entries  map[uint]*trigramEntry
+	postings map[string]map[uint]struct{}
+}
+
+func newTrigramIndex() *trigramIndex {
+	return &trigramIndex{
+		entries:  map[uint]*trigramEntry{},
+		postings: map[string]map[uint]struct{}{},
+	}
+}
+
+// build replaces the contents of the index with each place provided by the database.
+func (idx *trigramIndex) build(database *db.Database) error {
+	fresh := newTrigramIndex()
+	err := database.StreamPlaces(func(place *model.Place) error {
+		fresh.put(place)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries, idx.postings = fresh.entries, fresh.postings
+	idx.built = true
+	return nil
+}
+
+// ensureBuilt builds the index unless it has been built already.
+func (idx *trigramIndex) ensureBuilt(database *db.Database) error {
+	idx.mu.RLock()
+	built := idx.built
+	idx.mu.RUnlock()
+	if built {
+		return nil
+	}
+	return idx.build(database)
+}
+
+// put adds the place to the index, replacing any previous entry for the place.
+func (idx *trigramIndex) put(place *model.Place) {
+	entry := newTrigramEntry(place)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(place.ID)
+	idx.entries[place.ID] = entry
+	for _, set := range []trigramSet{entry.name, entry.description} {
+		for trigram := range set {
+			if idx.postings[trigram] == nil {
+				idx.postings[trigram] = map[uint]struct{}{}
+			}
+			idx.postings[trigram][place.ID] = struct{}{}
+		}
+	}
+}
+
+// remove drops the place identified from the index.
+func (idx *trigramIndex) remove(id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *trigramIndex) removeLocked(id uint) {
+	entry, ok := idx.entries[id]
+	if !ok {
+		return
+	}
+	delete(idx.entries, id)
+	for _, set := range []trigramSet{entry.name, entry.description} {
+		for trigram := range set {
+			delete(idx.postings[trigram], id)
+			if len(idx.postings[trigram]) == 0 {
+				delete(idx.postings, trigram)
+			}
+		}
+	}
+}
+
+// search returns the identifiers of the places similar to the text, ordered by decreasing similarity.
+func (idx *trigramIndex) search(text string) []uint {
+	query := trigrams(text)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	candidates := map[uint]struct{}{}
+	for trigram := range query {
+		for id := range idx.postings[trigram] {
+			candidates[id] = struct{}{}
+		}
+	}
+
+	scores := make(map[uint]float64, len(candidates))
+	ids := make([]uint, 0, len(candidates))
+	for id := range candidates {
+		entry := idx.entries[id]
+		if score, matched := placeSimilarity(query, entry.name, entry.description); matched {
+			scores[id] = score
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
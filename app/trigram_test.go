@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestTrigrams(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, trigramSet{"  d": {}, " da": {}, "dam": {}, "am ": {}}, trigrams("DAM!"))
+	assert.Empty(t, trigrams(" ,; "))
+
+	assert.InDelta(t, 0.75, trigrams("Hover Dam").similarity(trigrams("Hoover Dam")), 0.001)
+	assert.InDelta(t, 1.0, trigrams("orlando airport").coverage(trigrams("Orlando International Airport")), 0.001)
+	assert.Zero(t, trigrams("").similarity(trigrams("")))
+	assert.Zero(t, trigrams("").coverage(trigrams("dam")))
+}
+
+func TestTrigramIndex(t *testing.T) {
+	t.Parallel()
+	index := newTrigramIndex()
+	index.put(&model.Place{ID: 3, Name: "MCO", Description: "Orlando International Airport, FL, USA"})
+	index.put(&model.Place{ID: 4, Name: "Hoover Dam", Description: "Hoover Dam, Nevada, USA"})
+	index.put(&model.Place{ID: 6, Name: "MIA", Description: "Miami International Airport, FL, USA"})
+
+	assert.Equal(t, []uint{4}, index.search("Hover Dam"))
+	assert.Equal(t, []uint{3}, index.search("orlando airport"))
+	assert.Empty(t, index.search("volcano"))
+
+	index.put(&model.Place{ID: 4, Name: "Lake Mead", Description: "Lake Mead, Nevada, USA"})
+	assert.Empty(t, index.search("Hover Dam"))
+	assert.Equal(t, []uint{4}, index.search("lake meed"))
+
+	index.remove(4)
+	assert.Empty(t, index.search("lake meed"))
+	assert.NotContains(t, index.postings, "mea")
+	assert.Len(t, index.entries, 2)
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Limits on the values of a place.
+const (
+	maxPlaceNameLength        = 100
+	maxPlaceDescriptionLength = 2000
+)
+
+// validator accumulates the problems found with each field so that all are reported at once.
+type validator struct {
+	errors []FieldError
+}
+
+// add records the problem with the field.
+func (v *validator) add(field, message string) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: message})
+}
+
+// text checks a string does not exceed the maximum number of characters and holds no control characters,
+// other than line breaks and tabs when multiline.
+func (v *validator) text(field, value string, maxLength int, multiline bool) {
+	if !utf8.ValidString(value) {
+		v.add(field, "must be valid UTF-8")
+		return
+	}
+	if n := utf8.RuneCountInString(value); n > maxLength {
+		v.add(field, fmt.Sprintf("must be at most %d characters", maxLength))
+	}
+	if strings.IndexFunc(value, func(r rune) bool {
+		return unicode.IsControl(r) && !(multiline && (r == '\n' || r == '\r' || r == '\t'))
+	}) >= 0 {
+		v.add(field, "must not contain control characters")
+	}
+}
+
+// coordinate checks a number is finite and within the inclusive range.
+func (v *validator) coordinate(field string, value, limit float64) {
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < -limit || value > limit {
+		v.add(field, fmt.Sprintf("must be between %v and %v", -limit, limit))
+	}
+}
+
+// err provides a ValidationError describing every problem found, or nil when there were none.
+func (v *validator) err() *ValidationError {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return NewValidationError(v.errors)
+}
+
+// validatePlace checks every field of the place, reporting all problems found.
+// Surrounding whitespace is trimmed from the name of the place, and its tags are normalized.
+func (ctx *Context) validatePlace(place *model.Place) *ValidationError {
+	var v validator
+
+	place.Name = strings.TrimSpace(place.Name)
+	if place.Name == "" {
+		v.add("name", "is required")
+	} else {
+		v.text("name", place.Name, maxPlaceNameLength, false)
+	}
+	v.text("description", place.Description, maxPlaceDescriptionLength, true)
+	v.coordinate("latitude", place.Latitude, 90)
+	v.coordinate("longitude", place.Longitude, 180)
+	place.Tags = v.tags("tags", place.Tags)
+
+	return v.err()
+}
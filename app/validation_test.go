@@ -0,0 +1,90 @@
+package app
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestValidatePlace(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		place    model.Place
+		expected []string
+	}{
+		{
+			name:  "valid",
+			place: model.Place{Name: "Hoover Dam", Description: "Hoover Dam,\nNevada, USA", Latitude: 36.01604, Longitude: -114.73783},
+		},
+		{
+			name:     "blank name",
+			place:    model.Place{Name: "  \t"},
+			expected: []string{"name"},
+		},
+		{
+			name:     "long name",
+			place:    model.Place{Name: strings.Repeat("é", maxPlaceNameLength+1)},
+			expected: []string{"name"},
+		},
+		{
+			name:     "control characters",
+			place:    model.Place{Name: "Hoover\nDam", Description: "Nevada\x00"},
+			expected: []string{"name", "description"},
+		},
+		{
+			name:     "long description",
+			place:    model.Place{Name: "Hoover Dam", Description: strings.Repeat("x", maxPlaceDescriptionLength+1)},
+			expected: []string{"description"},
+		},
+		{
+			name:     "coordinates out of range",
+			place:    model.Place{Name: "Hoover Dam", Latitude: 500, Longitude: -999},
+			expected: []string{"latitude", "longitude"},
+		},
+		{
+			name:     "coordinates not finite",
+			place:    model.Place{Name: "Hoover Dam", Latitude: math.NaN(), Longitude: math.Inf(1)},
+			expected: []string{"latitude", "longitude"},
+		},
+		{
+			name:     "everything",
+			place:    model.Place{Latitude: -90.5, Longitude: 180.5},
+			expected: []string{"name", "latitude", "longitude"},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			verr := (&Context{}).validatePlace(&tc.place)
+			if len(tc.expected) == 0 {
+				if verr != nil {
+					t.Fatalf("expected no errors, but got %v", verr)
+				}
+				return
+			}
+			if verr == nil || len(verr.Errors) != len(tc.expected) {
+				t.Fatalf("expected errors for %v, but got %v", tc.expected, verr)
+			}
+			for i, field := range tc.expected {
+				if verr.Errors[i].Field != field {
+					t.Fatalf("expected errors for %v, but got %v", tc.expected, verr)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePlace_TrimsName(t *testing.T) {
+	t.Parallel()
+	place := &model.Place{Name: "  Hoover Dam "}
+	if verr := (&Context{}).validatePlace(place); verr != nil {
+		t.Fatal(verr)
+	}
+	if place.Name != "Hoover Dam" {
+		t.Fatalf("expected trimmed name, but got %q", place.Name)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manages the API keys granting clients access",
+	Long: `Manages the API keys granting clients access when AuthMethod is "apikey".
+
+Each key is granted scopes: places:read, places:write, or admin, which grants every scope.
+Each key also belongs to a tenant, and grants access to the places of that tenant alone.`,
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:          "create",
+	Short:        "Creates an API key, printing the key",
+	Long:         "Creates an API key, printing the key. Only a hash of the key is stored, so it cannot be shown again.",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		tenant, _ := cmd.Flags().GetString("tenant")
+
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		ctx, err := a.NewContext().ResolveTenant(tenant)
+		if err != nil {
+			return err
+		}
+		key, secret, err := ctx.CreateAPIKey(name, scopes)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "created API key %d; store the key now, as it cannot be shown again\n", key.ID)
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), secret)
+		return nil
+	},
+}
+
+var apiKeyListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "Lists every API key, including revoked keys",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		keys, err := a.NewContext().GetAPIKeys()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tTENANT\tCREATED\tREVOKED")
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix,
+				strings.Join(key.ScopeList(), ","), key.TenantID, key.CreatedAt.Format(time.RFC3339), formatRevokedAt(key))
+		}
+		return w.Flush()
+	},
+}
+
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:          "revoke ID",
+	Short:        "Revokes an API key, which is refused from then on",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		if err != nil {
+			return errors.Errorf("invalid API key ID %q", args[0])
+		}
+
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		if err = a.NewContext().RevokeAPIKey(uint(id)); err != nil {
+			return errors.Wrapf(err, "unable to revoke API key %d", id)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "revoked API key %d\n", id)
+		return nil
+	},
+}
+
+func formatRevokedAt(key *model.APIKey) string {
+	if key.RevokedAt == nil {
+		return "-"
+	}
+	return key.RevokedAt.Format(time.RFC3339)
+}
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+	apiKeyCmd.AddCommand(apiKeyCreateCmd, apiKeyListCmd, apiKeyRevokeCmd)
+
+	apiKeyCreateCmd.Flags().String("name", "", "name describing the client using the key")
+	apiKeyCreateCmd.Flags().StringSlice("scope", []string{model.ScopePlacesRead}, "scope granted to the key; repeatable")
+	apiKeyCreateCmd.Flags().String("tenant", model.DefaultTenant, "the tenant whose places the key grants access to")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/model"
+	"github.com/weesvc/weesvc-gorilla/transfer"
+)
+
+var exportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "Exports all places of a tenant as CSV, JSON lines, GeoJSON, KML, or GPX",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatName, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		tenant, _ := cmd.Flags().GetString("tenant")
+
+		format, err := transfer.ParseFormat(formatName)
+		if err != nil {
+			return err
+		}
+
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		ctx, err := a.NewContext().ResolveTenant(tenant)
+		if err != nil {
+			return err
+		}
+
+		var destination io.Writer = cmd.OutOrStdout()
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = file.Close()
+			}()
+			destination = file
+		}
+
+		writer, err := transfer.NewWriter(format, destination)
+		if err != nil {
+			return err
+		}
+		if err := ctx.ExportPlaces(writer.Write); err != nil {
+			return err
+		}
+		return writer.Close()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().String("format", string(transfer.FormatJSONL), "the format to export: csv, jsonl, geojson, kml, or gpx")
+	exportCmd.Flags().String("output", "", "the file to write; standard output if not set")
+	exportCmd.Flags().String("tenant", model.DefaultTenant, "the tenant whose places are exported")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+	"github.com/weesvc/weesvc-gorilla/model"
+	"github.com/weesvc/weesvc-gorilla/transfer"
+)
+
+var errDryRun = errors.New("dry run")
+
+// importSummary tallies the outcome of importing rows.
+type importSummary struct {
+	created, updated, failed int
+}
+
+// reject reports the row which could not be imported.
+func (s *importSummary) reject(report io.Writer, path string, row *transfer.Row) {
+	s.failed++
+	_, _ = fmt.Fprintf(report, "%s:%d: %v\n", path, row.Number, row.Err)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE...",
+	Short: "Imports places from CSV, JSON lines, or GeoJSON files",
+	Long: `Imports places from CSV, JSON lines, or GeoJSON files.
+
+Places are matched by name; existing places are updated and all others are created.
+Every row is validated before any changes are kept, and all files are imported within
+a single transaction so that a failed import leaves no partial data.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formatName, _ := cmd.Flags().GetString("format")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		tenant, _ := cmd.Flags().GetString("tenant")
+
+		if dryRun {
+			logrus.Info("=== DRY RUN ===")
+		}
+
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		ctx, err := a.NewContext().ResolveTenant(tenant)
+		if err != nil {
+			return err
+		}
+		summary := &importSummary{}
+		err = ctx.Database.Transaction(func(tx *db.Database) error {
+			txCtx := ctx.WithDatabase(tx)
+			for _, path := range args {
+				if err := importFile(cmd.ErrOrStderr(), txCtx, path, formatName, summary); err != nil {
+					return err
+				}
+			}
+			if summary.failed > 0 {
+				return fmt.Errorf("%d rows could not be imported; no changes were made", summary.failed)
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+
+		switch {
+		case errors.Is(err, errDryRun):
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d places would be created, %d updated\n", summary.created, summary.updated)
+			return nil
+		case err == nil:
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d places created, %d updated\n", summary.created, summary.updated)
+		}
+		return err
+	},
+}
+
+// importFile imports each row of the file, reporting rows which could not be imported to the writer.
+func importFile(report io.Writer, ctx *app.Context, path, formatName string, summary *importSummary) error {
+	format, err := importFormat(path, formatName)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // importing the files named by the operator is the purpose of this command
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader, err := transfer.NewReader(format, file)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read %s", path)
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to read %s", path)
+		}
+
+		if row.Err != nil {
+			summary.reject(report, path, row)
+			continue
+		}
+
+		var verr *app.ValidationError
+		created, err := ctx.ImportPlace(row.Place)
+		switch {
+		case errors.As(err, &verr):
+			row.Err = verr
+			summary.reject(report, path, row)
+		case err != nil:
+			// Database failures abort the import rather than being reported against the row
+			return errors.Wrapf(err, "%s:%d", path, row.Number)
+		case created:
+			summary.created++
+		default:
+			summary.updated++
+		}
+	}
+}
+
+func importFormat(path, formatName string) (transfer.Format, error) {
+	if formatName != "" {
+		return transfer.ParseFormat(formatName)
+	}
+	return transfer.FormatForPath(path)
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().String("format", "", "the format of the files: csv, jsonl, or geojson; detected from the file extension if not set")
+	importCmd.Flags().Bool("dry-run", false, "validate the files and report errors without importing them")
+	importCmd.Flags().String("tenant", model.DefaultTenant, "the tenant to import places into")
+}
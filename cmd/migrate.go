@@ -15,6 +15,8 @@ import (
 var migrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Readies the application database",
+	// Failed migrations are reported without the usage of the command
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		number, _ := cmd.Flags().GetInt("number")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
@@ -68,6 +70,9 @@ var migrateCmd = &cobra.Command{
 		}
 
 		for _, migration := range migrations.Migrations {
+			if migration.Number <= latest.Number {
+				continue
+			}
 			if migration.Number > uint(number) {
 				break
 			}
@@ -83,20 +88,18 @@ var migrateCmd = &cobra.Command{
 
 			if err := migration.Forwards(tx); err != nil {
 				logger.WithError(err).Error("unable to apply migration, rolling back")
-				if err := tx.Rollback().Error; err != nil {
-					logger.WithError(err).Error("unable to rollback...")
+				if rollbackErr := tx.Rollback().Error; rollbackErr != nil {
+					logger.WithError(rollbackErr).Error("unable to rollback...")
 				}
-				break
+				return errors.Wrapf(err, "unable to apply migration %d", migration.Number)
 			}
 
 			if err := tx.Commit().Error; err != nil {
-				logger.WithError(err).Error("unable to commit transaction")
-				break
+				return errors.Wrapf(err, "unable to commit migration %d", migration.Number)
 			}
 
 			if err := a.Database.Create(migration).Error; err != nil {
-				logger.WithError(err).Error("unable to create migration record")
-				break
+				return errors.Wrapf(err, "unable to record migration %d", migration.Number)
 			}
 		}
 
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/weesvc/weesvc-gorilla/app"
+	"github.com/weesvc/weesvc-gorilla/db"
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently removes places which have been in the trash too long",
+	Long: `Permanently removes places which have been in the trash too long.
+
+Deleted places remain within the trash, from which they may be restored, until purged.
+Ages are given in days, such as 30d, or as durations, such as 12h.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, _ := cmd.Flags().GetString("older-than")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return errors.Wrap(err, "invalid --older-than")
+		}
+
+		if dryRun {
+			logrus.Info("=== DRY RUN ===")
+		}
+
+		a, err := app.New()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = a.Close()
+		}()
+
+		var purged int64
+		err = a.Database.Transaction(func(tx *db.Database) (terr error) {
+			if purged, terr = a.NewContext().WithDatabase(tx).PurgePlaces(age); terr != nil {
+				return terr
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+
+		switch {
+		case errors.Is(err, errDryRun):
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d places would be purged\n", purged)
+			return nil
+		case err == nil:
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d places purged\n", purged)
+		}
+		return err
+	},
+}
+
+// parseAge reads an age given in days, such as `30d`, or as a duration, such as `12h`.
+func parseAge(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a number of days", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+
+	purgeCmd.Flags().String("older-than", "30d", "purge places deleted longer ago than this age")
+	purgeCmd.Flags().Bool("dry-run", false, "report the number of places to purge without removing them")
+}
@@ -20,16 +20,30 @@ import (
 
 func serveAPI(ctx context.Context, api *api.API) {
 	cors := handlers.CORS(
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "OPTIONS"}),
+		handlers.AllowedOrigins(api.Config.AllowedOrigins),
+		handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PATCH", "DELETE", "OPTIONS"}),
+		handlers.ExposedHeaders([]string{
+			"ETag", "Link", "X-Total-Count", "Idempotent-Replayed",
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After",
+		}),
+		handlers.AllowedHeaders([]string{
+			"Authorization", "Content-Type", "X-API-Key", "X-Tenant", "If-Match", "If-None-Match", "Idempotency-Key",
+		}),
 	)
 
 	router := mux.NewRouter()
 	api.Init(router.PathPrefix("/api").Subrouter())
 
+	// Cross-origin requests are refused unless origins are configured, as the CORS handler permits any origin
+	// given none
+	handler := http.Handler(router)
+	if len(api.Config.AllowedOrigins) > 0 {
+		handler = cors(router)
+	}
+
 	s := &http.Server{
 		Addr:        fmt.Sprintf(":%d", api.Config.Port),
-		Handler:     cors(router),
+		Handler:     handler,
 		ReadTimeout: 2 * time.Minute,
 	}
 
@@ -51,15 +65,19 @@ func serveAPI(ctx context.Context, api *api.API) {
 }
 
 var serveCmd = &cobra.Command{
-	Use:   "serve",
-	Short: "Starts the application server",
+	Use:          "serve",
+	Short:        "Starts the application server",
+	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		a, err := app.New()
 		if err != nil {
 			return err
 		}
 
-		api := api.New(a)
+		api, err := api.New(a)
+		if err != nil {
+			return err
+		}
 
 		ctx, cancel := context.WithCancel(context.Background())
 
@@ -73,6 +91,12 @@ var serveCmd = &cobra.Command{
 
 		var wg sync.WaitGroup
 
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.KeepSimilarityIndexWarm(ctx, api.Config.SimilarityIndexRefresh)
+		}()
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
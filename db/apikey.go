@@ -0,0 +1,40 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetAPIKeys retrieves every API key, including revoked keys, in order of creation.
+func (db *Database) GetAPIKeys() ([]*model.APIKey, error) {
+	var keys []*model.APIKey
+	return keys, wrapError(db.Order("id").Find(&keys).Error, "unable to find api keys")
+}
+
+// GetAPIKeyByHash retrieves the unrevoked API key having the given hash.
+func (db *Database) GetAPIKeyByHash(hash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := db.Where("hash = ? AND revoked_at IS NULL", hash).First(&key).Error
+	return &key, wrapError(err, "unable to get api key")
+}
+
+// CreateAPIKey adds the provided API key to the database.
+func (db *Database) CreateAPIKey(key *model.APIKey) error {
+	return wrapError(db.Create(key).Error, "unable to create api key")
+}
+
+// RevokeAPIKey prevents further use of the API key identified. Revoking a key which has already been revoked
+// reports ErrNotFound.
+func (db *Database) RevokeAPIKey(id uint) error {
+	result := db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", gorm.NowFunc())
+	if result.Error != nil {
+		return wrapError(result.Error, "unable to revoke api key")
+	}
+	if result.RowsAffected == 0 {
+		return wrapError(gorm.ErrRecordNotFound, "unable to revoke api key")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_APIKeys(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		key := &model.APIKey{Name: "reader", Prefix: "wee_abc", Hash: "abc", Scopes: model.ScopePlacesRead}
+		if !assert.NoError(t, placeDB.CreateAPIKey(key)) {
+			return
+		}
+		assert.ErrorIs(t, placeDB.CreateAPIKey(&model.APIKey{Name: "copy", Hash: "abc"}), ErrConflict)
+
+		found, err := placeDB.GetAPIKeyByHash("abc")
+		if assert.NoError(t, err) {
+			assert.Equal(t, key.ID, found.ID)
+			assert.Equal(t, []string{model.ScopePlacesRead}, found.ScopeList())
+		}
+
+		assert.NoError(t, placeDB.RevokeAPIKey(key.ID))
+		assert.ErrorIs(t, placeDB.RevokeAPIKey(key.ID), ErrNotFound)
+		_, err = placeDB.GetAPIKeyByHash("abc")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		keys, err := placeDB.GetAPIKeys()
+		if assert.NoError(t, err) && assert.Len(t, keys, 1) {
+			assert.NotNil(t, keys[0].RevokedAt)
+		}
+	})
+}
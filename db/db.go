@@ -13,6 +13,11 @@ import (
 // Database represents the data access object.
 type Database struct {
 	*gorm.DB
+
+	// tenant, when not empty, limits every read and change of places to those of the tenant
+	tenant string
+	// visibleOwner, when not empty, limits reads of places to those which are shared or owned by the principal
+	visibleOwner string
 }
 
 // New creates a new instance of the data access object given configuration settings.
@@ -24,5 +29,53 @@ func New(config *Config) (*Database, error) {
 
 	db.LogMode(config.Verbose)
 
-	return &Database{db}, nil
+	return &Database{DB: db}, nil
+}
+
+// VisibleTo limits reads of places through the returned database to those which are shared, having no owner, or
+// which are owned by the principal identified. An empty owner lifts the limit.
+func (db *Database) VisibleTo(owner string) *Database {
+	ret := *db
+	ret.visibleOwner = owner
+	return &ret
+}
+
+// ForTenant limits reads and changes of places and tags through the returned database to those of the tenant, which
+// also owns the places and tags created. An empty tenant lifts the limit, as for maintenance spanning every tenant.
+func (db *Database) ForTenant(tenant string) *Database {
+	ret := *db
+	ret.tenant = tenant
+	return &ret
+}
+
+// whereTenant restricts the scope to the places of the tenant of the database, if any.
+func (db *Database) whereTenant(scope *gorm.DB) *gorm.DB {
+	return db.whereTenantOf(scope, "places")
+}
+
+// whereTenantOf restricts the scope to the rows of the table belonging to the tenant of the database, if any.
+func (db *Database) whereTenantOf(scope *gorm.DB, table string) *gorm.DB {
+	if db.tenant == "" {
+		return scope
+	}
+	return scope.Where(table+".tenant_id = ?", db.tenant)
+}
+
+// whereVisible restricts the scope to the places visible through the database.
+func (db *Database) whereVisible(scope *gorm.DB) *gorm.DB {
+	scope = db.whereTenant(scope)
+	if db.visibleOwner == "" {
+		return scope
+	}
+	return scope.Where("places.owner_id IS NULL OR places.owner_id = ?", db.visibleOwner)
+}
+
+// Transaction runs fn within a database transaction, committing when fn succeeds and rolling back otherwise.
+// When the database is already within a transaction, fn joins the existing transaction.
+func (db *Database) Transaction(fn func(tx *Database) error) error {
+	return db.DB.Transaction(func(gormTx *gorm.DB) error {
+		tx := *db
+		tx.DB = gormTx
+		return fn(&tx)
+	})
 }
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// Errors reported by the database independent of the dialect.
+var (
+	// ErrNotFound indicates the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict indicates a record would duplicate a unique value of an existing record
+	ErrConflict = errors.New("record conflicts with an existing record")
+	// ErrConstraint indicates a record would violate any other constraint, such as a required value
+	ErrConstraint = errors.New("record violates a constraint")
+)
+
+// dialectError associates an error reported by a database driver with its dialect-independent kind.
+type dialectError struct {
+	kind error
+	err  error
+}
+
+func (e *dialectError) Error() string {
+	return e.err.Error()
+}
+
+// Is reports the error to be of its dialect-independent kind.
+func (e *dialectError) Is(target error) bool {
+	return target == e.kind //nolint:errorlint // kinds are sentinel errors
+}
+
+// Unwrap provides the error reported by the driver.
+func (e *dialectError) Unwrap() error {
+	return e.err
+}
+
+// translateError classifies errors reported by gorm and the database drivers as ErrNotFound, ErrConflict,
+// or ErrConstraint when applicable. Other errors are returned as is.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if gorm.IsRecordNotFoundError(err) {
+		return ErrNotFound
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return &dialectError{kind: ErrConflict, err: err}
+		default:
+			return &dialectError{kind: ErrConstraint, err: err}
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "23" {
+		if pqErr.Code.Name() == "unique_violation" {
+			return &dialectError{kind: ErrConflict, err: err}
+		}
+		return &dialectError{kind: ErrConstraint, err: err}
+	}
+
+	return err
+}
+
+// wrapError classifies the error, as with translateError, and annotates it with the message.
+func wrapError(err error, message string) error {
+	return errors.Wrap(translateError(err), message)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestTranslateError(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "sqlite3 unique", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, expected: ErrConflict},
+		{name: "sqlite3 primary key", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}, expected: ErrConflict},
+		{name: "sqlite3 not null", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}, expected: ErrConstraint},
+		{name: "postgres unique", err: &pq.Error{Code: "23505"}, expected: ErrConflict},
+		{name: "postgres not null", err: &pq.Error{Code: "23502"}, expected: ErrConstraint},
+		{name: "postgres check", err: &pq.Error{Code: "23514"}, expected: ErrConstraint},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := wrapError(tc.err, "unable to create place")
+			assert.ErrorIs(t, err, tc.expected)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	assert.NoError(t, translateError(nil))
+	assert.Equal(t, ErrNotFound, translateError(gorm.ErrRecordNotFound))
+	other := errors.New("connection refused")
+	assert.Equal(t, other, translateError(other))
+}
+
+func TestDatabase_Errors(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		_, err := placeDB.GetPlaceByID(99)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		duplicateName := &model.Place{ID: 30, Name: "Hoover Dam"}
+		assert.ErrorIs(t, placeDB.CreatePlace(duplicateName), ErrConflict)
+
+		duplicateID := &model.Place{ID: 1, Name: "Kerid Crater"}
+		assert.ErrorIs(t, placeDB.CreatePlace(duplicateID), ErrConflict)
+
+		now := time.Now()
+		err = placeDB.Exec("INSERT INTO places (id, name, created_at, updated_at) VALUES (31, NULL, ?, ?)", now, now).Error
+		assert.ErrorIs(t, translateError(err), ErrConstraint)
+	})
+}
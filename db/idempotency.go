@@ -0,0 +1,61 @@
+package db
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// ReserveIdempotencyKey records the key as in progress, unless the key is already recorded and has not expired,
+// in which case the existing record is returned instead.
+func (db *Database) ReserveIdempotencyKey(key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	now := gorm.NowFunc().UTC()
+	if err := db.Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{}).Error; err != nil {
+		return nil, wrapError(err, "unable to remove expired idempotency keys")
+	}
+
+	existing, err := db.getIdempotencyKey(key.Key)
+	if existing != nil || err != nil {
+		return existing, err
+	}
+
+	key.StatusCode = 0
+	if err = db.Create(key).Error; err != nil {
+		// A concurrent request may have reserved the same key in the meantime
+		if existing, _ = db.getIdempotencyKey(key.Key); existing != nil {
+			return existing, nil
+		}
+		return nil, wrapError(err, "unable to reserve idempotency key")
+	}
+	return nil, nil
+}
+
+func (db *Database) getIdempotencyKey(key string) (*model.IdempotencyKey, error) {
+	var existing model.IdempotencyKey
+	err := db.Where("idempotency_key = ?", key).First(&existing).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err, "unable to get idempotency key")
+	}
+	return &existing, nil
+}
+
+// CompleteIdempotencyKey records the response to the request made with a reserved key.
+func (db *Database) CompleteIdempotencyKey(key *model.IdempotencyKey) error {
+	err := db.Model(&model.IdempotencyKey{}).
+		Where("idempotency_key = ?", key.Key).
+		Updates(map[string]interface{}{
+			"status_code": key.StatusCode,
+			"header":      key.Header,
+			"body":        key.Body,
+		}).Error
+	return wrapError(err, "unable to complete idempotency key")
+}
+
+// ReleaseIdempotencyKey removes a reserved key, allowing the request to be attempted again.
+func (db *Database) ReleaseIdempotencyKey(key string) error {
+	err := db.Where("idempotency_key = ?", key).Delete(&model.IdempotencyKey{}).Error
+	return wrapError(err, "unable to release idempotency key")
+}
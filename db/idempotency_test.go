@@ -0,0 +1,64 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_ReserveIdempotencyKey(t *testing.T) {
+	t.Parallel()
+	keyDB := setupDatabase(t)
+
+	now := gorm.NowFunc().UTC()
+	key := &model.IdempotencyKey{Key: "retry", Fingerprint: "first", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	existing, err := keyDB.ReserveIdempotencyKey(key)
+	if !assert.NoError(t, err) || !assert.Nil(t, existing) {
+		return
+	}
+
+	// A retry while in progress finds the reservation
+	retry := &model.IdempotencyKey{Key: "retry", Fingerprint: "second", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	existing, err = keyDB.ReserveIdempotencyKey(retry)
+	if assert.NoError(t, err) && assert.NotNil(t, existing) {
+		assert.Equal(t, "first", existing.Fingerprint)
+		assert.False(t, existing.Completed())
+	}
+
+	key.StatusCode = 201
+	key.Body = `{"id":11}`
+	if assert.NoError(t, keyDB.CompleteIdempotencyKey(key)) {
+		existing, err = keyDB.ReserveIdempotencyKey(retry)
+		if assert.NoError(t, err) && assert.NotNil(t, existing) {
+			assert.Equal(t, 201, existing.StatusCode)
+			assert.Equal(t, `{"id":11}`, existing.Body)
+		}
+	}
+
+	// Once released the key may be reserved again
+	if assert.NoError(t, keyDB.ReleaseIdempotencyKey("retry")) {
+		existing, err = keyDB.ReserveIdempotencyKey(retry)
+		assert.NoError(t, err)
+		assert.Nil(t, existing)
+	}
+}
+
+func TestDatabase_ReserveIdempotencyKey_Expired(t *testing.T) {
+	t.Parallel()
+	keyDB := setupDatabase(t)
+
+	now := gorm.NowFunc().UTC()
+	expired := &model.IdempotencyKey{Key: "expired", Fingerprint: "first", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	if !assert.NoError(t, keyDB.Create(expired).Error) {
+		return
+	}
+
+	key := &model.IdempotencyKey{Key: "expired", Fingerprint: "second", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	existing, err := keyDB.ReserveIdempotencyKey(key)
+	assert.NoError(t, err)
+	assert.Nil(t, existing)
+}
@@ -1,34 +1,194 @@
 package db
 
 import (
+	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 
+	"github.com/weesvc/weesvc-gorilla/geo"
 	"github.com/weesvc/weesvc-gorilla/model"
 )
 
-// GetPlaces retrieves all available places from the database.
-func (db *Database) GetPlaces() ([]*model.Place, error) {
+// GetPlaces retrieves a single page of places from the database.
+func (db *Database) GetPlaces(query *PlaceQuery) (*PlacePage, error) {
+	query.normalize()
+
+	scope := db.whereVisible(query.filter(db.DB))
+
+	page := &PlacePage{}
+	if err := scope.Model(&model.Place{}).Count(&page.Total).Error; err != nil {
+		return nil, wrapError(err, "unable to count places")
+	}
+
+	if err := query.paginate(scope).Find(&page.Places).Error; err != nil {
+		return nil, wrapError(err, "unable to find places")
+	}
+
+	if len(page.Places) > query.Limit {
+		page.Places = page.Places[:query.Limit]
+		page.Next = newPlaceCursor(query, page.Places[query.Limit-1])
+	}
+
+	return page, nil
+}
+
+// StreamPlaces calls fn with each place, in order of identifier, reading rows from a database cursor
+// so that the full set of places is never held in memory.
+func (db *Database) StreamPlaces(fn func(place *model.Place) error) error {
+	rows, err := db.whereVisible(db.Model(&model.Place{})).Order("id").Rows()
+	if err != nil {
+		return wrapError(err, "unable to stream places")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var place model.Place
+		if err := db.ScanRows(rows, &place); err != nil {
+			return wrapError(err, "unable to read place")
+		}
+		if err := fn(&place); err != nil {
+			return err
+		}
+	}
+	return wrapError(rows.Err(), "unable to stream places")
+}
+
+// GetPlacesInBox retrieves every place located within the bounding box.
+func (db *Database) GetPlacesInBox(box geo.BoundingBox) ([]*model.Place, error) {
 	var places []*model.Place
-	return places, errors.Wrap(db.Find(&places).Error, "unable to find places")
+	return places, wrapError(whereInBox(db.whereVisible(db.DB), box).Find(&places).Error, "unable to find places")
+}
+
+// whereInBox restricts the scope to places located within the bounding box.
+func whereInBox(scope *gorm.DB, box geo.BoundingBox) *gorm.DB {
+	scope = scope.Where("latitude BETWEEN ? AND ?", box.MinLatitude, box.MaxLatitude)
+	if box.CrossesAntimeridian() {
+		return scope.Where("longitude >= ? OR longitude <= ?", box.MinLongitude, box.MaxLongitude)
+	}
+	return scope.Where("longitude BETWEEN ? AND ?", box.MinLongitude, box.MaxLongitude)
 }
 
 // GetPlaceByID retrieves a single place given its identifier.
 func (db *Database) GetPlaceByID(id uint) (*model.Place, error) {
 	var place model.Place
-	return &place, errors.Wrap(db.First(&place, id).Error, "unable to get place")
+	return &place, wrapError(db.whereVisible(db.DB).First(&place, id).Error, "unable to get place")
+}
+
+// GetPlaceByIDUnscoped retrieves a single place given its identifier, including places within the trash.
+func (db *Database) GetPlaceByIDUnscoped(id uint) (*model.Place, error) {
+	var place model.Place
+	return &place, wrapError(db.whereVisible(db.Unscoped()).First(&place, id).Error, "unable to get place")
 }
 
-// CreatePlace add the provided place to the database.
+// GetPlaceByName retrieves a single place given its name.
+func (db *Database) GetPlaceByName(name string) (*model.Place, error) {
+	var place model.Place
+	err := db.whereTenant(db.DB).Where("name = ?", name).First(&place).Error
+	return &place, wrapError(err, "unable to find place by name")
+}
+
+// ErrVersionMismatch indicates a place has been modified since the version provided was read.
+var ErrVersionMismatch = errors.New("place has been modified")
+
+// CreatePlace add the provided place to the database, along with its tags. The place belongs to the tenant of the
+// database, if any, or otherwise to its own tenant or the default tenant.
 func (db *Database) CreatePlace(place *model.Place) error {
-	return errors.Wrap(db.Create(place).Error, "unable to create place")
+	place.Version = 1
+	if db.tenant != "" {
+		place.TenantID = db.tenant
+	} else if place.TenantID == "" {
+		place.TenantID = model.DefaultTenant
+	}
+	return db.Transaction(func(tx *Database) error {
+		if err := tx.Create(place).Error; err != nil {
+			return wrapError(err, "unable to create place")
+		}
+		if place.Tags == nil {
+			return nil
+		}
+		return tx.setPlaceTags(place)
+	})
 }
 
-// UpdatePlace updates the existing place in the database.
+// UpdatePlace updates the existing place in the database, provided it remains at the version of the given place.
+// The tags of the place are replaced unless nil. Upon success, the version of the place is incremented.
 func (db *Database) UpdatePlace(place *model.Place) error {
-	return errors.Wrap(db.Save(place).Error, "unable to update place")
+	return db.Transaction(func(tx *Database) error {
+		if err := tx.updatePlace(place); err != nil {
+			return err
+		}
+		if place.Tags == nil {
+			return nil
+		}
+		return tx.setPlaceTags(place)
+	})
+}
+
+func (db *Database) updatePlace(place *model.Place) error {
+	now := gorm.NowFunc()
+	result := db.whereTenant(db.Model(&model.Place{})).
+		Where("id = ? AND version = ?", place.ID, place.Version).
+		Updates(map[string]interface{}{
+			"name":        place.Name,
+			"description": place.Description,
+			"latitude":    place.Latitude,
+			"longitude":   place.Longitude,
+			"updated_at":  now,
+			"version":     gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return wrapError(result.Error, "unable to update place")
+	}
+	if result.RowsAffected == 0 {
+		return wrapError(db.versionConflict(place.ID), "unable to update place")
+	}
+
+	place.UpdatedAt = now
+	place.Version++
+	return nil
+}
+
+// versionConflict determines why a change conditional upon the version of a place affected no rows.
+func (db *Database) versionConflict(id uint) error {
+	var place model.Place
+	if err := db.whereTenant(db.DB).Select("id").First(&place, id).Error; err != nil {
+		return err
+	}
+	return ErrVersionMismatch
+}
+
+// UpsertPlace creates the place, or updates the existing place having the same name.
+// The returned flag reports whether a new place was created.
+func (db *Database) UpsertPlace(place *model.Place) (created bool, err error) {
+	existing, err := db.GetPlaceByName(place.Name)
+	if errors.Is(err, ErrNotFound) {
+		return true, db.CreatePlace(place)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	place.ID = existing.ID
+	place.CreatedAt = existing.CreatedAt
+	place.Version = existing.Version
+	return false, db.UpdatePlace(place)
+}
+
+// DeletePlace moves the place to the trash, provided it remains at the version of the given place.
+// Deleted places are excluded from all reads other than those of the trash.
+func (db *Database) DeletePlace(place *model.Place) error {
+	result := db.whereTenant(db.DB).Where("version = ?", place.Version).Delete(&model.Place{ID: place.ID})
+	if result.Error != nil {
+		return wrapError(result.Error, "unable to delete place")
+	}
+	if result.RowsAffected == 0 {
+		return wrapError(db.versionConflict(place.ID), "unable to delete place")
+	}
+	return nil
 }
 
-// DeletePlaceByID removes a single place from the database given its identifier.
+// DeletePlaceByID moves a single place to the trash given its identifier.
 func (db *Database) DeletePlaceByID(id uint) error {
-	return errors.Wrap(db.Delete(&model.Place{}, id).Error, "unable to delete place")
+	return wrapError(db.whereTenant(db.DB).Delete(&model.Place{}, id).Error, "unable to delete place")
 }
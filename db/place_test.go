@@ -3,8 +3,10 @@ package db
 import (
 	"context"
 	"log"
+	"path/filepath"
 	"testing"
 
+	"github.com/weesvc/weesvc-gorilla/geo"
 	"github.com/weesvc/weesvc-gorilla/model"
 	"github.com/weesvc/weesvc-gorilla/testhelpers"
 
@@ -15,9 +17,92 @@ func TestDatabase_GetPlaces(t *testing.T) {
 	t.Parallel()
 	placeDB := setupDatabase(t)
 
-	places, err := placeDB.GetPlaces()
-	assert.NoError(t, err)
-	assert.Equal(t, 10, len(places))
+	page, err := placeDB.GetPlaces(&PlaceQuery{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 10, len(page.Places))
+		assert.Equal(t, 10, page.Total)
+		assert.Nil(t, page.Next)
+	}
+}
+
+func TestDatabase_GetPlaces_Paginated(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	query := &PlaceQuery{Sort: SortByName, Limit: 4}
+	var names []string
+	for pages := 0; ; pages++ {
+		page, err := placeDB.GetPlaces(query)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, 10, page.Total)
+		for _, place := range page.Places {
+			names = append(names, place.Name)
+		}
+		if page.Next == nil {
+			assert.Equal(t, 2, pages)
+			break
+		}
+		query.After = page.Next
+	}
+
+	assert.Equal(t, []string{
+		"Bellagio Fountains", "Grand Canyon", "Hollywood Studios", "Hoover Dam", "MCO",
+		"MIA", "Mount Rushmore", "ORD", "Red Rocks", "Unknown",
+	}, names)
+}
+
+func TestDatabase_StreamPlaces(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	var ids []uint
+	err := placeDB.StreamPlaces(func(place *model.Place) error {
+		ids = append(ids, place.ID)
+		return nil
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+	}
+}
+
+func TestDatabase_GetPlacesInBox(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	// Florida
+	places, err := placeDB.GetPlacesInBox(geo.BoundingBox{
+		MinLatitude: 24.5, MinLongitude: -87.6, MaxLatitude: 31, MaxLongitude: -80,
+	})
+	if assert.NoError(t, err) {
+		var ids []uint
+		for _, place := range places {
+			ids = append(ids, place.ID)
+		}
+		assert.ElementsMatch(t, []uint{3, 6, 9}, ids)
+	}
+}
+
+func TestDatabase_GetPlaces_InBox(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	// Crosses the antimeridian to cover the western United States
+	page, err := placeDB.GetPlaces(&PlaceQuery{
+		Sort: SortByName,
+		Box:  &geo.BoundingBox{MinLatitude: 30, MinLongitude: 170, MaxLatitude: 45, MaxLongitude: -100},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, page.Total)
+		var names []string
+		for _, place := range page.Places {
+			names = append(names, place.Name)
+		}
+		assert.Equal(t, []string{
+			"Bellagio Fountains", "Grand Canyon", "Hoover Dam", "Mount Rushmore", "Red Rocks",
+		}, names)
+	}
 }
 
 func TestDatabase_GetPlaceByID(t *testing.T) {
@@ -76,6 +161,7 @@ func TestDatabase_UpdatePlace(t *testing.T) {
 			Description: "The Alamo, San Antonio, TX, USA",
 			Latitude:    29.42590,
 			Longitude:   -98.48625,
+			Version:     original.Version,
 		}
 		if assert.NoError(t, placeDB.UpdatePlace(changes)) {
 			// Verify the updated place
@@ -88,11 +174,73 @@ func TestDatabase_UpdatePlace(t *testing.T) {
 				assert.Equal(t, changes.Longitude, updated.Longitude)
 				assert.Equal(t, original.CreatedAt, updated.CreatedAt)
 				assert.NotEqual(t, original.UpdatedAt, updated.UpdatedAt)
+				assert.Equal(t, original.Version+1, updated.Version)
+				assert.Equal(t, updated.Version, changes.Version)
 			}
 		}
 	}
 }
 
+func TestDatabase_UpdatePlace_StaleVersion(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	first, err := placeDB.GetPlaceByID(7)
+	if !assert.NoError(t, err) {
+		return
+	}
+	second := *first
+
+	first.Description = "First change"
+	if assert.NoError(t, placeDB.UpdatePlace(first)) {
+		second.Description = "Second change"
+		assert.ErrorIs(t, placeDB.UpdatePlace(&second), ErrVersionMismatch)
+		assert.ErrorIs(t, placeDB.DeletePlace(&second), ErrVersionMismatch)
+
+		current, err := placeDB.GetPlaceByID(7)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "First change", current.Description)
+		}
+	}
+
+	missing := &model.Place{ID: 99, Name: "Missing", Version: 1}
+	assert.EqualError(t, placeDB.UpdatePlace(missing), "unable to update place: record not found")
+}
+
+func TestDatabase_UpsertPlace(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	existing := &model.Place{
+		Name:        "MIA",
+		Description: "Miami International Airport",
+		Latitude:    25.79516,
+		Longitude:   -80.27959,
+	}
+	created, err := placeDB.UpsertPlace(existing)
+	if assert.NoError(t, err) {
+		assert.False(t, created)
+		assert.Equal(t, uint(6), existing.ID)
+		updated, err := placeDB.GetPlaceByID(6)
+		if assert.NoError(t, err) {
+			assert.Equal(t, existing.Description, updated.Description)
+		}
+	}
+
+	newPlace := &model.Place{
+		ID:        21,
+		Name:      "Kerid Crater",
+		Latitude:  64.04126,
+		Longitude: -20.88530,
+	}
+	created, err = placeDB.UpsertPlace(newPlace)
+	if assert.NoError(t, err) {
+		assert.True(t, created)
+		_, err = placeDB.GetPlaceByID(newPlace.ID)
+		assert.NoError(t, err)
+	}
+}
+
 func TestDatabase_DeletePlaceByID(t *testing.T) {
 	t.Parallel()
 	placeDB := setupDatabase(t)
@@ -108,6 +256,68 @@ func TestDatabase_DeletePlaceByID(t *testing.T) {
 	}
 }
 
+func TestDatabase_VisibleTo(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		owner, other := "apikey:1", "apikey:2"
+		owned := &model.Place{ID: 20, Name: "Owned", OwnerID: &owner}
+		othersPlace := &model.Place{ID: 21, Name: "Not Owned", OwnerID: &other}
+		if !assert.NoError(t, placeDB.CreatePlace(owned)) || !assert.NoError(t, placeDB.CreatePlace(othersPlace)) {
+			return
+		}
+
+		page, err := placeDB.VisibleTo(owner).GetPlaces(&PlaceQuery{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 11, page.Total)
+		}
+		_, err = placeDB.VisibleTo(owner).GetPlaceByID(owned.ID)
+		assert.NoError(t, err)
+		_, err = placeDB.VisibleTo(owner).GetPlaceByID(othersPlace.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		page, err = placeDB.VisibleTo("").GetPlaces(&PlaceQuery{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 12, page.Total)
+		}
+	})
+}
+
+// setupSQLiteDatabase creates a database within a temporary file, prepared the same way as the Postgres container.
+func setupSQLiteDatabase(t *testing.T) *Database {
+	placeDB, err := New(&Config{
+		DatabaseURI: filepath.Join(t.TempDir(), "test.db"),
+		Dialect:     "sqlite3",
+		Verbose:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = placeDB.Close()
+	})
+
+	if err := testhelpers.PrepareDatabase(placeDB.DB); err != nil {
+		t.Fatal(err)
+	}
+	return placeDB
+}
+
+// forEachDialect runs the test in parallel against a seeded database of each supported dialect.
+func forEachDialect(t *testing.T, test func(t *testing.T, placeDB *Database)) {
+	t.Helper()
+	dialects := map[string]func(t *testing.T) *Database{
+		"postgres": setupDatabase,
+		"sqlite3":  setupSQLiteDatabase,
+	}
+	for dialect, setup := range dialects {
+		setup := setup // pin
+		t.Run(dialect, func(t *testing.T) {
+			t.Parallel()
+			test(t, setup(t))
+		})
+	}
+}
+
 // setupDatabase creates an isolated `Database` instance backed by a Postgres Testcontainer.
 func setupDatabase(t *testing.T) *Database {
 	ctx := context.Background()
@@ -132,5 +342,9 @@ func setupDatabase(t *testing.T) *Database {
 		}
 	})
 
+	if err = testhelpers.PrepareDatabase(placeDB.DB); err != nil {
+		log.Fatal(err)
+	}
+
 	return placeDB
 }
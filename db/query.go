@@ -0,0 +1,191 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/geo"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// PlaceSort identifies a column which places may be ordered by.
+type PlaceSort string
+
+// Supported sort columns for places.
+const (
+	SortByCreatedAt PlaceSort = "created_at"
+	SortByUpdatedAt PlaceSort = "updated_at"
+	SortByName      PlaceSort = "name"
+)
+
+// DefaultPageSize is the number of places returned when a query does not specify a limit.
+const DefaultPageSize = 100
+
+// ParsePlaceSort converts the external representation of a sort column, e.g. `name` or `-created_at`,
+// into the column and direction to order by.
+func ParsePlaceSort(s string) (sort PlaceSort, descending bool, err error) {
+	if s == "" {
+		return SortByCreatedAt, false, nil
+	}
+	if s[0] == '-' {
+		descending = true
+		s = s[1:]
+	}
+	switch sort = PlaceSort(s); sort {
+	case SortByCreatedAt, SortByUpdatedAt, SortByName:
+		return sort, descending, nil
+	default:
+		return "", false, fmt.Errorf("unsupported sort %q", s)
+	}
+}
+
+// PlaceQuery describes a single page of places to retrieve.
+type PlaceQuery struct {
+	// Sort is the column to order by; defaults to SortByCreatedAt
+	Sort PlaceSort
+	// Descending reverses the sort order
+	Descending bool
+	// Limit is the maximum number of places to return; defaults to DefaultPageSize
+	Limit int
+	// After resumes the listing following the place identified by the cursor
+	After *PlaceCursor
+
+	// Box restricts the listing to places located within the bounding box
+	Box *geo.BoundingBox
+	// Text to search for within the name and description of places
+	Text string
+	// Tags restricts the listing to places having every tag, or any of the tags when AnyTag is set
+	Tags   []string
+	AnyTag bool
+}
+
+// PlacePage is a single page of results for a PlaceQuery.
+type PlacePage struct {
+	Places []*model.Place
+	// Total is the number of places matching the query across all pages
+	Total int
+	// Next identifies where the following page begins; nil when this is the last page
+	Next *PlaceCursor
+}
+
+// PlaceCursor marks the position of the last place returned within a page.
+type PlaceCursor struct {
+	Sort       PlaceSort `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Key        string    `json:"k"`
+	ID         uint      `json:"i"`
+}
+
+func newPlaceCursor(query *PlaceQuery, place *model.Place) *PlaceCursor {
+	cursor := &PlaceCursor{Sort: query.Sort, Descending: query.Descending, ID: place.ID}
+	switch query.Sort {
+	case SortByName:
+		cursor.Key = place.Name
+	case SortByUpdatedAt:
+		cursor.Key = place.UpdatedAt.Format(time.RFC3339Nano)
+	case SortByCreatedAt:
+		cursor.Key = place.CreatedAt.Format(time.RFC3339Nano)
+	}
+	return cursor
+}
+
+// Encode creates the opaque string representation of the cursor.
+func (c *PlaceCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePlaceCursor parses a cursor previously created by Encode.
+func DecodePlaceCursor(s string) (*PlaceCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	var cursor PlaceCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if _, _, err := ParsePlaceSort(string(cursor.Sort)); err != nil || cursor.Sort == "" {
+		return nil, errors.New("malformed cursor")
+	}
+	if _, err := cursor.key(); err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+	return &cursor, nil
+}
+
+// key provides the cursor position in the type of the sorted column.
+func (c *PlaceCursor) key() (interface{}, error) {
+	if c.Sort == SortByName {
+		return c.Key, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, c.Key)
+	return t, err
+}
+
+// Matches determines whether the cursor was created for the provided query ordering.
+func (c *PlaceCursor) Matches(q *PlaceQuery) bool {
+	return c.Sort == q.Sort && c.Descending == q.Descending
+}
+
+func (q *PlaceQuery) normalize() {
+	if q.Sort == "" {
+		q.Sort = SortByCreatedAt
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+}
+
+// filter restricts the provided scope to the places matching the query.
+func (q *PlaceQuery) filter(scope *gorm.DB) *gorm.DB {
+	if q.Box != nil {
+		scope = whereInBox(scope, *q.Box)
+	}
+	if len(q.Tags) > 0 {
+		scope = q.whereTagged(scope)
+	}
+	return scope
+}
+
+// whereTagged restricts the scope to places having the tags of the query.
+func (q *PlaceQuery) whereTagged(scope *gorm.DB) *gorm.DB {
+	const taggedSQL = `places.id IN (
+		SELECT place_tags.place_id FROM place_tags JOIN tags ON tags.id = place_tags.tag_id
+		WHERE tags.name IN (?) GROUP BY place_tags.place_id`
+	if q.AnyTag {
+		return scope.Where(taggedSQL+")", q.Tags)
+	}
+	return scope.Where(taggedSQL+" HAVING COUNT(*) = ?)", q.Tags, len(q.Tags))
+}
+
+// paginate applies ordering, the cursor position, and limit to the provided scope.
+// One more row than the limit is requested to determine whether another page exists.
+func (q *PlaceQuery) paginate(scope *gorm.DB) *gorm.DB {
+	column, param := string(q.Sort), "?"
+	if q.Sort != SortByName && scope.Dialect().GetName() == "sqlite3" {
+		// SQLite stores timestamps as text in more than one format, so compare the instants instead
+		column, param = fmt.Sprintf("julianday(%s)", column), "julianday(?)"
+	}
+	direction, comparison := "ASC", ">"
+	if q.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if q.After != nil {
+		key, _ := q.After.key()
+		scope = scope.Where(
+			fmt.Sprintf("%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND id %[2]s ?)", column, comparison, param),
+			key, key, q.After.ID)
+	}
+
+	return scope.
+		Order(column + " " + direction).
+		Order("id " + direction).
+		Limit(q.Limit + 1)
+}
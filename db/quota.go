@@ -0,0 +1,23 @@
+package db
+
+import (
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// IncrementQuotaCounter counts a request by the client to the group of routes during the day, returning the number
+// of requests counted for the day, including this one. Concurrent requests are each counted exactly once.
+func (db *Database) IncrementQuotaCounter(client, routeGroup, day string) (requests int, err error) {
+	const incrementSQL = `
+		INSERT INTO quota_counters(client, route_group, day, requests) VALUES (?, ?, ?, 1)
+		ON CONFLICT(client, route_group, day) DO UPDATE SET requests = quota_counters.requests + 1
+		RETURNING requests
+	`
+	err = db.Raw(incrementSQL, client, routeGroup, day).Row().Scan(&requests)
+	return requests, wrapError(err, "unable to count request against quota")
+}
+
+// DeleteQuotaCountersBefore removes the counters of the days preceding the given day.
+func (db *Database) DeleteQuotaCountersBefore(day string) error {
+	err := db.Where("day < ?", day).Delete(&model.QuotaCounter{}).Error
+	return wrapError(err, "unable to remove past quota counters")
+}
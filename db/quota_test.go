@@ -0,0 +1,34 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_IncrementQuotaCounter(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		for expected := 1; expected <= 3; expected++ {
+			requests, err := placeDB.IncrementQuotaCounter("apikey:1", "read", "2024-05-01")
+			if assert.NoError(t, err) {
+				assert.Equal(t, expected, requests)
+			}
+		}
+		requests, err := placeDB.IncrementQuotaCounter("apikey:1", "write", "2024-05-01")
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, requests)
+		}
+		requests, err = placeDB.IncrementQuotaCounter("apikey:1", "read", "2024-05-02")
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, requests)
+		}
+
+		if assert.NoError(t, placeDB.DeleteQuotaCountersBefore("2024-05-02")) {
+			requests, err = placeDB.IncrementQuotaCounter("apikey:1", "read", "2024-05-01")
+			if assert.NoError(t, err) {
+				assert.Equal(t, 1, requests)
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// CreatePlaceRevision records the revision as the next revision of its place.
+func (db *Database) CreatePlaceRevision(revision *model.PlaceRevision) error {
+	var latest struct {
+		Revision uint
+	}
+	err := db.Table("place_revisions").
+		Select("COALESCE(MAX(revision), 0) AS revision").
+		Where("place_id = ?", revision.PlaceID).
+		Scan(&latest).Error
+	if err != nil {
+		return wrapError(err, "unable to find latest place revision")
+	}
+
+	revision.Revision = latest.Revision + 1
+	return wrapError(db.Create(revision).Error, "unable to create place revision")
+}
+
+// GetPlaceRevisions retrieves every revision of the place identified, in order of revision.
+func (db *Database) GetPlaceRevisions(placeID uint) ([]*model.PlaceRevision, error) {
+	var revisions []*model.PlaceRevision
+	err := db.Where("place_id = ?", placeID).Order("revision").Find(&revisions).Error
+	return revisions, wrapError(err, "unable to find place revisions")
+}
+
+// GetPlaceRevision retrieves a single revision of the place identified.
+func (db *Database) GetPlaceRevision(placeID, revision uint) (*model.PlaceRevision, error) {
+	var placeRevision model.PlaceRevision
+	err := db.Where("place_id = ? AND revision = ?", placeID, revision).First(&placeRevision).Error
+	return &placeRevision, wrapError(err, "unable to get place revision")
+}
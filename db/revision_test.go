@@ -0,0 +1,37 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_PlaceRevisions(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		for _, action := range []string{model.RevisionUpdate, model.RevisionDelete} {
+			revision := &model.PlaceRevision{PlaceID: 3, Action: action, Snapshot: "{}", Changes: "{}"}
+			assert.NoError(t, placeDB.CreatePlaceRevision(revision))
+		}
+		other := &model.PlaceRevision{PlaceID: 4, Action: model.RevisionUpdate, Snapshot: "{}", Changes: "{}"}
+		assert.NoError(t, placeDB.CreatePlaceRevision(other))
+		assert.Equal(t, uint(1), other.Revision)
+
+		revisions, err := placeDB.GetPlaceRevisions(3)
+		if assert.NoError(t, err) && assert.Len(t, revisions, 2) {
+			assert.Equal(t, uint(1), revisions[0].Revision)
+			assert.Equal(t, model.RevisionUpdate, revisions[0].Action)
+			assert.Equal(t, uint(2), revisions[1].Revision)
+			assert.Equal(t, model.RevisionDelete, revisions[1].Action)
+		}
+
+		revision, err := placeDB.GetPlaceRevision(3, 2)
+		if assert.NoError(t, err) {
+			assert.Equal(t, model.RevisionDelete, revision.Action)
+		}
+		_, err = placeDB.GetPlaceRevision(3, 3)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Markers surrounding the matched terms within search snippets, the only markup of the otherwise HTML-escaped text.
+const (
+	SnippetStart = "<mark>"
+	SnippetStop  = "</mark>"
+)
+
+// searchResult is a place along with its relevance to the search and the snippet of its matching text.
+type searchResult struct {
+	model.Place
+	Highlight string `gorm:"column:snippet"`
+}
+
+// SearchPlaces retrieves the places matching the text of the query, ordered by decreasing relevance.
+// Only the first page of results is returned as relevance does not provide a stable order for cursors.
+func (db *Database) SearchPlaces(query *PlaceQuery) (*PlacePage, error) {
+	query.normalize()
+	if len(searchTerms(query.Text)) == 0 {
+		// Text consisting of only punctuation matches nothing
+		return &PlacePage{Places: []*model.Place{}}, nil
+	}
+
+	var search func(scope *gorm.DB, query *PlaceQuery) *gorm.DB
+	switch {
+	case db.Dialect().GetName() == "postgres":
+		search = searchPostgres
+	case db.hasSQLiteSearchIndex():
+		search = searchSQLite
+	default:
+		search = searchPattern
+	}
+
+	scope := search(query.filter(whereNotDeleted(db.whereVisible(db.DB.Table("places")))), query)
+
+	page := &PlacePage{}
+	if err := scope.Count(&page.Total).Error; err != nil {
+		return nil, wrapError(err, "unable to count places")
+	}
+
+	var results []*searchResult
+	if err := scope.Limit(query.Limit).Scan(&results).Error; err != nil {
+		return nil, wrapError(err, "unable to search places")
+	}
+
+	page.Places = make([]*model.Place, len(results))
+	for i, result := range results {
+		place := result.Place
+		snippet := escapeSnippet(result.Highlight)
+		if snippet == "" {
+			snippet = highlight(place.Name, query.Text)
+			if strings.Contains(highlight(place.Description, query.Text), SnippetStart) {
+				snippet = highlight(place.Description, query.Text)
+			}
+		}
+		place.Snippet = &snippet
+		page.Places[i] = &place
+	}
+	return page, nil
+}
+
+// searchPostgres matches against the text search vector of places, ranking names above descriptions.
+func searchPostgres(scope *gorm.DB, query *PlaceQuery) *gorm.DB {
+	const tsQuery = "websearch_to_tsquery('english', ?)"
+	return scope.
+		Select("places.*, ts_rank(search, "+tsQuery+") AS rank, ts_headline('english', "+
+			"CASE WHEN to_tsvector('english', coalesce(description, '')) @@ "+tsQuery+" THEN description ELSE name END, "+
+			tsQuery+", 'StartSel="+SnippetStart+", StopSel="+SnippetStop+", MaxFragments=1') AS snippet",
+			query.Text, query.Text, query.Text).
+		Where("search @@ "+tsQuery, query.Text).
+		Order("rank DESC").
+		Order("id")
+}
+
+// hasSQLiteSearchIndex determines whether the FTS5 index of places exists, which depends upon
+// the SQLite build used when migrating the database.
+func (db *Database) hasSQLiteSearchIndex() bool {
+	if db.Dialect().GetName() != "sqlite3" {
+		return false
+	}
+	var count int
+	err := db.Table("sqlite_master").Where("type = 'table' AND name = 'places_fts'").Count(&count).Error
+	return err == nil && count > 0
+}
+
+// searchSQLite matches against the FTS5 index of places, ranking with the BM25 algorithm.
+func searchSQLite(scope *gorm.DB, query *PlaceQuery) *gorm.DB {
+	return scope.
+		Select("places.*, places_fts.rank AS rank, "+
+			"snippet(places_fts, -1, ?, ?, '…', 16) AS snippet", SnippetStart, SnippetStop).
+		Joins("JOIN places_fts ON places_fts.rowid = places.id").
+		Where("places_fts MATCH ?", ftsQuery(query.Text)).
+		Order("places_fts.rank").
+		Order("places.id")
+}
+
+// ftsQuery quotes each term of the text so that FTS5 query syntax is matched literally;
+// places must contain every term.
+func ftsQuery(text string) string {
+	terms := searchTerms(text)
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchPattern matches places whose name or description contains every term of the text,
+// for databases lacking a text search index. Places with the text within their name are ranked first.
+func searchPattern(scope *gorm.DB, query *PlaceQuery) *gorm.DB {
+	for _, term := range searchTerms(query.Text) {
+		pattern := "%" + escapeLike(term) + "%"
+		scope = scope.Where(`name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+	return scope.
+		Select("places.*").
+		Order(gorm.Expr(`CASE WHEN name LIKE ? ESCAPE '\' THEN 0 ELSE 1 END`, "%"+escapeLike(query.Text)+"%")).
+		Order("name").
+		Order("id")
+}
+
+// searchTerms splits the text into words, ignoring punctuation.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// escapeSnippet escapes the text of a snippet produced by the database as HTML, preserving the markers around
+// the matched terms.
+func escapeSnippet(snippet string) string {
+	parts := strings.Split(snippet, SnippetStart)
+	for i, part := range parts {
+		stops := strings.Split(part, SnippetStop)
+		for j, text := range stops {
+			stops[j] = html.EscapeString(text)
+		}
+		parts[i] = strings.Join(stops, SnippetStop)
+	}
+	return strings.Join(parts, SnippetStart)
+}
+
+// highlight escapes s as HTML, surrounding each occurrence of the terms of the text within it, ignoring case.
+func highlight(s, text string) string {
+	lower := strings.ToLower(s)
+	if len(lower) != len(s) {
+		// Offsets within the lowercase text would not correspond to the original
+		return html.EscapeString(s)
+	}
+	marked := make([]bool, len(s))
+	for _, term := range searchTerms(strings.ToLower(text)) {
+		for start := 0; ; {
+			i := strings.Index(lower[start:], term)
+			if i < 0 {
+				break
+			}
+			for j := start + i; j < start+i+len(term); j++ {
+				marked[j] = true
+			}
+			start += i + len(term)
+		}
+	}
+
+	var b strings.Builder
+	for start := 0; start < len(s); {
+		end := start + 1
+		for end < len(s) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			b.WriteString(SnippetStart)
+		}
+		b.WriteString(html.EscapeString(s[start:end]))
+		if marked[start] {
+			b.WriteString(SnippetStop)
+		}
+		start = end
+	}
+	return b.String()
+}
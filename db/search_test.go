@@ -0,0 +1,84 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/geo"
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_SearchPlaces(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		page, err := placeDB.SearchPlaces(&PlaceQuery{Text: "airport"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 3, page.Total)
+			var names []string
+			for _, place := range page.Places {
+				names = append(names, place.Name)
+				if assert.NotNil(t, place.Snippet) {
+					assert.Contains(t, strings.ToLower(*place.Snippet), SnippetStart+"airport"+SnippetStop)
+				}
+			}
+			assert.ElementsMatch(t, []string{"MCO", "MIA", "ORD"}, names)
+		}
+
+		florida := geo.BoundingBox{MinLatitude: 24.5, MinLongitude: -87.6, MaxLatitude: 31, MaxLongitude: -80}
+		page, err = placeDB.SearchPlaces(&PlaceQuery{Text: "airport", Box: &florida, Limit: 1})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, page.Total)
+			assert.Equal(t, 1, len(page.Places))
+		}
+
+		page, err = placeDB.SearchPlaces(&PlaceQuery{Text: `hoover "dam`})
+		if assert.NoError(t, err) && assert.Equal(t, 1, len(page.Places)) {
+			assert.Equal(t, "Hoover Dam", page.Places[0].Name)
+		}
+
+		page, err = placeDB.SearchPlaces(&PlaceQuery{Text: `";--`})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, page.Total)
+		}
+
+		page, err = placeDB.SearchPlaces(&PlaceQuery{Text: "volcano"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, page.Total)
+			assert.Empty(t, page.Places)
+		}
+	})
+}
+
+func TestDatabase_SearchPlaces_EscapesSnippet(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		err := placeDB.CreatePlace(&model.Place{
+			Name:        "Kerid Crater",
+			Description: `Volcanic crater <script>alert("lake")</script> & lake`,
+			Latitude:    64.04126,
+			Longitude:   -20.88530,
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		page, err := placeDB.SearchPlaces(&PlaceQuery{Text: "lake"})
+		if assert.NoError(t, err) && assert.Equal(t, 1, len(page.Places)) && assert.NotNil(t, page.Places[0].Snippet) {
+			snippet := *page.Places[0].Snippet
+			assert.NotContains(t, snippet, "<script>")
+			assert.Contains(t, snippet, "&lt;script&gt;")
+			assert.Contains(t, snippet, SnippetStart+"lake"+SnippetStop)
+		}
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "Orlando International <mark>Airport</mark>, FL, USA",
+		highlight("Orlando International Airport, FL, USA", "airport"))
+	assert.Equal(t, "<mark>Hoover</mark> <mark>Dam</mark>", highlight("Hoover Dam", "dam hoover"))
+	assert.Equal(t, "Red Rocks", highlight("Red Rocks", "airport"))
+	assert.Equal(t, "&lt;b&gt;<mark>Red</mark>&lt;/b&gt; Rocks", highlight("<b>Red</b> Rocks", "red"))
+}
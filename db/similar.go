@@ -0,0 +1,118 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Thresholds of similarity for fuzzy matches, matching the defaults of the Postgres pg_trgm extension.
+const (
+	// NameSimilarityThreshold is the least similarity between the text and the name of a place
+	NameSimilarityThreshold = 0.3
+	// DescriptionSimilarityThreshold is the least similarity between the text and the most similar
+	// words within the description of a place
+	DescriptionSimilarityThreshold = 0.6
+)
+
+// maxIDsPerQuery bounds the identifiers bound within a single query, remaining well below the
+// variable limits of each supported dialect.
+const maxIDsPerQuery = 500
+
+// similarResult is a place along with its similarity to the text of a fuzzy search.
+type similarResult struct {
+	model.Place
+	Score float64 `gorm:"column:similarity"`
+}
+
+// SupportsSimilarity reports whether the database ranks places by trigram similarity itself.
+// Otherwise similar places must be found by an index maintained by the application.
+func (db *Database) SupportsSimilarity() bool {
+	return db.Dialect().GetName() == "postgres"
+}
+
+// GetSimilarPlaces retrieves the places whose name or description resemble the text, ordered by decreasing
+// similarity, using the trigram indexes of Postgres. Only the first page of results is returned.
+func (db *Database) GetSimilarPlaces(text string, limit int) (*PlacePage, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	// The `%` and `<%` operators apply the thresholds configured for pg_trgm, allowing use of the indexes
+	scope := whereNotDeleted(db.whereVisible(db.DB.Table("places"))).
+		Select("places.*, GREATEST(similarity(name, ?), word_similarity(?, coalesce(description, ''))) AS similarity",
+			text, text).
+		Where("name % ? OR ? <% description", text, text).
+		Order("similarity DESC").
+		Order("id")
+
+	page := &PlacePage{}
+	if err := scope.Count(&page.Total).Error; err != nil {
+		return nil, wrapError(err, "unable to count places")
+	}
+
+	var results []*similarResult
+	if err := scope.Limit(limit).Scan(&results).Error; err != nil {
+		return nil, wrapError(err, "unable to find similar places")
+	}
+
+	page.Places = make([]*model.Place, len(results))
+	for i, result := range results {
+		place := result.Place
+		score := result.Score
+		place.Similarity = &score
+		page.Places[i] = &place
+	}
+	return page, nil
+}
+
+// SuggestPlaces retrieves places having a name, or a word within their name, beginning with the prefix,
+// ignoring case. Names beginning with the prefix are listed first.
+func (db *Database) SuggestPlaces(prefix string, limit int) ([]*model.Place, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	like := "LIKE"
+	if db.Dialect().GetName() == "postgres" {
+		like = "ILIKE"
+	}
+	pattern := escapeLike(prefix) + "%"
+
+	var places []*model.Place
+	err := db.whereVisible(db.DB).
+		Where(`name `+like+` ? ESCAPE '\' OR name `+like+` ? ESCAPE '\'`, pattern, "% "+pattern).
+		Order(gorm.Expr(`CASE WHEN name `+like+` ? ESCAPE '\' THEN 0 ELSE 1 END`, pattern)).
+		Order("name").
+		Order("id").
+		Limit(limit).
+		Find(&places).Error
+	return places, wrapError(err, "unable to suggest places")
+}
+
+// GetPlacesByIDs retrieves the places having any of the identifiers, in order of identifier.
+// Identifiers of places which do not exist are ignored.
+func (db *Database) GetPlacesByIDs(ids []uint) ([]*model.Place, error) {
+	ids = append([]uint(nil), ids...)
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	places := make([]*model.Place, 0, len(ids))
+	for start := 0; start < len(ids); start += maxIDsPerQuery {
+		end := start + maxIDsPerQuery
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var chunk []*model.Place
+		err := db.whereVisible(db.DB).Where("id IN (?)", ids[start:end]).Order("id").Find(&chunk).Error
+		if err != nil {
+			return nil, wrapError(err, "unable to get places")
+		}
+		places = append(places, chunk...)
+	}
+	return places, nil
+}
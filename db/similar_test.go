@@ -0,0 +1,73 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_GetSimilarPlaces(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	page, err := placeDB.GetSimilarPlaces("Hover Dam", 0)
+	if assert.NoError(t, err) && assert.NotEmpty(t, page.Places) {
+		assert.Equal(t, "Hoover Dam", page.Places[0].Name)
+		if assert.NotNil(t, page.Places[0].Similarity) {
+			assert.GreaterOrEqual(t, *page.Places[0].Similarity, NameSimilarityThreshold)
+		}
+	}
+
+	page, err = placeDB.GetSimilarPlaces("orlando airport", 1)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(page.Places)) {
+		assert.Equal(t, "MCO", page.Places[0].Name)
+	}
+
+	page, err = placeDB.GetSimilarPlaces("volcano", 0)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, page.Total)
+	}
+}
+
+func TestDatabase_SuggestPlaces(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		places, err := placeDB.SuggestPlaces("ho", 0)
+		if assert.NoError(t, err) {
+			var names []string
+			for _, place := range places {
+				names = append(names, place.Name)
+			}
+			assert.Equal(t, []string{"Hollywood Studios", "Hoover Dam"}, names)
+		}
+
+		// Words within names are completed after names beginning with the prefix
+		places, err = placeDB.SuggestPlaces("r", 0)
+		if assert.NoError(t, err) {
+			var names []string
+			for _, place := range places {
+				names = append(names, place.Name)
+			}
+			assert.Equal(t, []string{"Red Rocks", "Mount Rushmore"}, names)
+		}
+
+		places, err = placeDB.SuggestPlaces("%", 0)
+		if assert.NoError(t, err) {
+			assert.Empty(t, places)
+		}
+	})
+}
+
+func TestDatabase_GetPlacesByIDs(t *testing.T) {
+	t.Parallel()
+	placeDB := setupDatabase(t)
+
+	places, err := placeDB.GetPlacesByIDs([]uint{6, 99, 3})
+	if assert.NoError(t, err) {
+		var ids []uint
+		for _, place := range places {
+			ids = append(ids, place.ID)
+		}
+		assert.Equal(t, []uint{3, 6}, ids)
+	}
+}
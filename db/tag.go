@@ -0,0 +1,226 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetTags retrieves every tag of the tenant, ordered by name.
+func (db *Database) GetTags() ([]*model.Tag, error) {
+	var tags []*model.Tag
+	return tags, wrapError(db.whereTags().Order("name").Find(&tags).Error, "unable to find tags")
+}
+
+// GetTagByID retrieves a single tag of the tenant given its identifier.
+func (db *Database) GetTagByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	return &tag, wrapError(db.whereTags().First(&tag, id).Error, "unable to get tag")
+}
+
+// CreateTag adds the provided tag to the database. The tag belongs to the tenant of the database, if any, or
+// otherwise to its own tenant or the default tenant.
+func (db *Database) CreateTag(tag *model.Tag) error {
+	if db.tenant != "" {
+		tag.TenantID = db.tenant
+	} else if tag.TenantID == "" {
+		tag.TenantID = model.DefaultTenant
+	}
+	return wrapError(db.Create(tag).Error, "unable to create tag")
+}
+
+// UpdateTag renames the existing tag.
+func (db *Database) UpdateTag(tag *model.Tag) error {
+	now := gorm.NowFunc()
+	result := db.whereTags().Model(&model.Tag{}).
+		Where("id = ?", tag.ID).
+		Updates(map[string]interface{}{"name": tag.Name, "updated_at": now})
+	if result.Error != nil {
+		return wrapError(result.Error, "unable to update tag")
+	}
+	if result.RowsAffected == 0 {
+		return wrapError(gorm.ErrRecordNotFound, "unable to update tag")
+	}
+
+	tag.UpdatedAt = now
+	return nil
+}
+
+// DeleteTag removes the tag from the database, along with its associations to places.
+func (db *Database) DeleteTag(id uint) error {
+	return db.Transaction(func(tx *Database) error {
+		// The tag is removed first, so that only the places of its own tenant are untagged
+		result := tx.whereTags().Delete(&model.Tag{}, id)
+		if result.Error != nil {
+			return wrapError(result.Error, "unable to delete tag")
+		}
+		if result.RowsAffected == 0 {
+			return wrapError(gorm.ErrRecordNotFound, "unable to delete tag")
+		}
+		err := tx.Where("tag_id = ?", id).Delete(&model.PlaceTag{}).Error
+		return wrapError(err, "unable to untag places")
+	})
+}
+
+// GetTaggedPlaces retrieves every place of the tenant having the tag, including those within the trash, in order of
+// identifier.
+func (db *Database) GetTaggedPlaces(tagID uint) ([]*model.Place, error) {
+	var places []*model.Place
+	err := db.whereTenant(db.Unscoped()).
+		Where("id IN (?)", db.Table("place_tags").Select("place_id").Where("tag_id = ?", tagID).QueryExpr()).
+		Order("id").
+		Find(&places).Error
+	return places, wrapError(err, "unable to find tagged places")
+}
+
+// TouchPlaces marks each of the places as updated, incrementing their versions, as when a tag they share changes.
+func (db *Database) TouchPlaces(places []*model.Place) error {
+	now := gorm.NowFunc()
+	ids := make([]uint, len(places))
+	for i, place := range places {
+		ids[i] = place.ID
+	}
+
+	for start := 0; start < len(ids); start += maxIDsPerQuery {
+		end := start + maxIDsPerQuery
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		err := db.whereTenant(db.Unscoped().Model(&model.Place{})).
+			Where("id IN (?)", ids[start:end]).
+			Updates(map[string]interface{}{"updated_at": now, "version": gorm.Expr("version + 1")}).Error
+		if err != nil {
+			return wrapError(err, "unable to update places")
+		}
+	}
+
+	for _, place := range places {
+		place.UpdatedAt = now
+		place.Version++
+	}
+	return nil
+}
+
+// whereTags restricts the tags read and changed to those of the tenant of the database, if any.
+func (db *Database) whereTags() *gorm.DB {
+	return db.whereTenantOf(db.DB, "tags")
+}
+
+// placeTagName is the name of a tag associated with a place.
+type placeTagName struct {
+	PlaceID uint
+	Name    string
+}
+
+// LoadPlaceTags retrieves the tags of each of the places, ordered by name.
+func (db *Database) LoadPlaceTags(places []*model.Place) error {
+	byID := make(map[uint]*model.Place, len(places))
+	ids := make([]uint, 0, len(places))
+	for _, place := range places {
+		place.Tags = []string{}
+		byID[place.ID] = place
+		ids = append(ids, place.ID)
+	}
+
+	for start := 0; start < len(ids); start += maxIDsPerQuery {
+		end := start + maxIDsPerQuery
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var names []*placeTagName
+		err := db.Table("place_tags").
+			Select("place_tags.place_id, tags.name").
+			Joins("JOIN tags ON tags.id = place_tags.tag_id").
+			Where("place_tags.place_id IN (?)", ids[start:end]).
+			Order("tags.name").
+			Scan(&names).Error
+		if err != nil {
+			return wrapError(err, "unable to get place tags")
+		}
+		for _, name := range names {
+			place := byID[name.PlaceID]
+			place.Tags = append(place.Tags, name.Name)
+		}
+	}
+	return nil
+}
+
+// setPlaceTags replaces the tags of the place with the named tags, creating any which do not yet exist. The tags are
+// those of the tenant of the database, if any, or otherwise of the tenant of the place.
+func (db *Database) setPlaceTags(place *model.Place) error {
+	if err := db.untagPlace(place.ID); err != nil {
+		return err
+	}
+	if len(place.Tags) == 0 {
+		return nil
+	}
+
+	tagDB := db
+	if db.tenant == "" {
+		tenant := place.TenantID
+		if tenant == "" {
+			tenant = model.DefaultTenant
+		}
+		tagDB = db.ForTenant(tenant)
+	}
+
+	var existing []*model.Tag
+	if err := tagDB.whereTags().Where("name IN (?)", place.Tags).Find(&existing).Error; err != nil {
+		return wrapError(err, "unable to find tags")
+	}
+	tagIDs := make(map[string]uint, len(place.Tags))
+	for _, tag := range existing {
+		tagIDs[tag.Name] = tag.ID
+	}
+
+	for _, name := range place.Tags {
+		if _, ok := tagIDs[name]; !ok {
+			tag := &model.Tag{Name: name}
+			if err := tagDB.CreateTag(tag); err != nil {
+				return err
+			}
+			tagIDs[name] = tag.ID
+		}
+		if err := db.Create(&model.PlaceTag{PlaceID: place.ID, TagID: tagIDs[name]}).Error; err != nil {
+			return wrapError(err, "unable to tag place")
+		}
+	}
+
+	sort.Strings(place.Tags)
+	return nil
+}
+
+// GetTagCounts counts the places matching the query having each tag, ordered by decreasing count,
+// along with the total number of places matching the query.
+func (db *Database) GetTagCounts(query *PlaceQuery) (counts []*model.TagCount, total int, err error) {
+	places := query.filter(whereNotDeleted(db.whereVisible(db.DB.Table("places"))))
+	if err = places.Count(&total).Error; err != nil {
+		return nil, 0, wrapError(err, "unable to count places")
+	}
+
+	err = db.Table("place_tags").
+		Select("tags.name AS name, COUNT(*) AS count").
+		Joins("JOIN tags ON tags.id = place_tags.tag_id").
+		Where("place_tags.place_id IN (?)", places.Select("places.id").QueryExpr()).
+		Group("tags.name").
+		Order("count DESC").
+		Order("tags.name").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, 0, wrapError(err, "unable to count tags")
+	}
+	if counts == nil {
+		counts = []*model.TagCount{}
+	}
+	return counts, total, nil
+}
+
+// untagPlace removes the associations of a place with its tags.
+func (db *Database) untagPlace(id uint) error {
+	err := db.Where("place_id = ?", id).Delete(&model.PlaceTag{}).Error
+	return wrapError(err, "unable to untag place")
+}
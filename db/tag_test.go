@@ -0,0 +1,119 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_Tags(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		tags, err := placeDB.GetTags()
+		if assert.NoError(t, err) {
+			var names []string
+			for _, tag := range tags {
+				names = append(names, tag.Name)
+			}
+			assert.Equal(t, []string{"airport", "fl", "nv", "park"}, names)
+		}
+
+		tag := &model.Tag{Name: "museum"}
+		if assert.NoError(t, placeDB.CreateTag(tag)) {
+			assert.NotZero(t, tag.ID)
+			assert.ErrorIs(t, placeDB.CreateTag(&model.Tag{Name: "museum"}), ErrConflict)
+
+			tag.Name = "museums"
+			assert.NoError(t, placeDB.UpdateTag(tag))
+			assert.NoError(t, placeDB.DeleteTag(tag.ID))
+			assert.ErrorIs(t, placeDB.DeleteTag(tag.ID), ErrNotFound)
+		}
+	})
+}
+
+func TestDatabase_PlaceTags(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		place, err := placeDB.GetPlaceByID(4)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if assert.NoError(t, placeDB.LoadPlaceTags([]*model.Place{place})) {
+			assert.Equal(t, []string{"nv"}, place.Tags)
+		}
+
+		// Tags are created as needed when applied to a place
+		place.Tags = []string{"nv", "dam"}
+		if assert.NoError(t, placeDB.UpdatePlace(place)) {
+			assert.Equal(t, []string{"dam", "nv"}, place.Tags)
+		}
+
+		// Nil tags leave the tags of the place unchanged
+		place.Tags = nil
+		if assert.NoError(t, placeDB.UpdatePlace(place)) && assert.NoError(t, placeDB.LoadPlaceTags([]*model.Place{place})) {
+			assert.Equal(t, []string{"dam", "nv"}, place.Tags)
+		}
+
+		page, err := placeDB.GetPlaces(&PlaceQuery{Tags: []string{"airport", "fl"}, Sort: SortByName})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 2, page.Total)
+			assert.Equal(t, "MCO", page.Places[0].Name)
+		}
+
+		page, err = placeDB.GetPlaces(&PlaceQuery{Tags: []string{"airport", "fl"}, AnyTag: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 4, page.Total)
+		}
+
+		counts, total, err := placeDB.GetTagCounts(&PlaceQuery{Tags: []string{"fl"}})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 3, total)
+			assert.Equal(t, []*model.TagCount{{Name: "fl", Count: 3}, {Name: "airport", Count: 2}}, counts)
+		}
+
+		if assert.NoError(t, placeDB.DeletePlace(place)) {
+			counts, _, err = placeDB.GetTagCounts(&PlaceQuery{})
+			if assert.NoError(t, err) {
+				assert.NotContains(t, counts, &model.TagCount{Name: "nv", Count: 2})
+				assert.Contains(t, counts, &model.TagCount{Name: "nv", Count: 1})
+			}
+		}
+	})
+}
+
+func TestDatabase_TouchTaggedPlaces(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		tags, err := placeDB.GetTags()
+		if !assert.NoError(t, err) {
+			return
+		}
+		var nv *model.Tag
+		for _, tag := range tags {
+			if tag.Name == "nv" {
+				nv = tag
+			}
+		}
+
+		// Places within the trash keep their tags, so are changed along with them
+		if !assert.NoError(t, placeDB.DeletePlaceByID(4)) {
+			return
+		}
+		places, err := placeDB.GetTaggedPlaces(nv.ID)
+		if !assert.NoError(t, err) || !assert.Len(t, places, 2) {
+			return
+		}
+		assert.Equal(t, uint(2), places[0].ID)
+		assert.Equal(t, uint(4), places[1].ID)
+
+		if assert.NoError(t, placeDB.TouchPlaces(places)) {
+			place, err := placeDB.GetPlaceByID(2)
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint(2), place.Version)
+				assert.Equal(t, places[0].Version, place.Version)
+			}
+		}
+	})
+}
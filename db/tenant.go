@@ -0,0 +1,22 @@
+package db
+
+import (
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// GetTenants retrieves every tenant, ordered by identifier.
+func (db *Database) GetTenants() ([]*model.Tenant, error) {
+	var tenants []*model.Tenant
+	return tenants, wrapError(db.Order("id").Find(&tenants).Error, "unable to find tenants")
+}
+
+// GetTenantByID retrieves a single tenant given its identifier.
+func (db *Database) GetTenantByID(id string) (*model.Tenant, error) {
+	var tenant model.Tenant
+	return &tenant, wrapError(db.Where("id = ?", id).First(&tenant).Error, "unable to get tenant")
+}
+
+// CreateTenant adds the provided tenant to the database, reporting ErrConflict when the identifier is taken.
+func (db *Database) CreateTenant(tenant *model.Tenant) error {
+	return wrapError(db.Create(tenant).Error, "unable to create tenant")
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_Tenants(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		assert.NoError(t, placeDB.CreateTenant(&model.Tenant{ID: "acme", Name: "Acme"}))
+		assert.ErrorIs(t, placeDB.CreateTenant(&model.Tenant{ID: "acme", Name: "Acme"}), ErrConflict)
+		tenants, err := placeDB.GetTenants()
+		if assert.NoError(t, err) && assert.Len(t, tenants, 2) {
+			assert.Equal(t, "acme", tenants[0].ID)
+			assert.Equal(t, model.DefaultTenant, tenants[1].ID)
+		}
+		_, err = placeDB.GetTenantByID("globex")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestDatabase_ForTenant(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		acmeDB := placeDB.ForTenant("acme")
+		defaultDB := acmeDB.ForTenant(model.DefaultTenant)
+
+		// Names are unique within each tenant alone
+		place := &model.Place{ID: 20, Name: "MCO"}
+		if !assert.NoError(t, acmeDB.CreatePlace(place)) {
+			return
+		}
+		assert.Equal(t, "acme", place.TenantID)
+		assert.ErrorIs(t, acmeDB.CreatePlace(&model.Place{ID: 21, Name: "MCO"}), ErrConflict)
+
+		page, err := acmeDB.GetPlaces(&PlaceQuery{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, page.Total)
+		}
+		page, err = defaultDB.GetPlaces(&PlaceQuery{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 10, page.Total)
+		}
+		existing, err := acmeDB.GetPlaceByName("MCO")
+		if assert.NoError(t, err) {
+			assert.Equal(t, place.ID, existing.ID)
+		}
+
+		// The places of other tenants can be neither read nor changed
+		_, err = defaultDB.GetPlaceByID(place.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		places, err := defaultDB.GetPlacesByIDs([]uint{1, place.ID})
+		if assert.NoError(t, err) && assert.Len(t, places, 1) {
+			assert.Equal(t, uint(1), places[0].ID)
+		}
+		assert.ErrorIs(t, defaultDB.UpdatePlace(&model.Place{ID: place.ID, Name: "Taken", Version: 1}), ErrNotFound)
+		assert.ErrorIs(t, defaultDB.DeletePlace(&model.Place{ID: place.ID, Version: 1}), ErrNotFound)
+		assert.NoError(t, defaultDB.DeletePlaceByID(place.ID))
+
+		unchanged, err := acmeDB.GetPlaceByID(place.ID)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "MCO", unchanged.Name)
+			assert.Equal(t, uint(1), unchanged.Version)
+		}
+	})
+}
+
+func TestDatabase_ForTenant_Tags(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		acmeDB := placeDB.ForTenant("acme")
+		defaultDB := placeDB.ForTenant(model.DefaultTenant)
+
+		tags, err := acmeDB.GetTags()
+		if assert.NoError(t, err) {
+			assert.Empty(t, tags)
+		}
+
+		// Tags applied to places are created within the tenant of the place, despite sharing names with others
+		place := &model.Place{ID: 20, Name: "MCO", Tags: []string{"airport"}}
+		if !assert.NoError(t, acmeDB.CreatePlace(place)) {
+			return
+		}
+		tags, err = acmeDB.GetTags()
+		if !assert.NoError(t, err) || !assert.Len(t, tags, 1) {
+			return
+		}
+		airport := tags[0]
+		assert.Equal(t, "acme", airport.TenantID)
+		assert.NoError(t, acmeDB.CreateTag(&model.Tag{Name: "fl"}))
+		assert.ErrorIs(t, acmeDB.CreateTag(&model.Tag{Name: "fl"}), ErrConflict)
+
+		// Tags of other tenants can be neither read nor changed
+		_, err = defaultDB.GetTagByID(airport.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, defaultDB.UpdateTag(&model.Tag{ID: airport.ID, Name: "airfield"}), ErrNotFound)
+		assert.ErrorIs(t, defaultDB.DeleteTag(airport.ID), ErrNotFound)
+		if assert.NoError(t, acmeDB.LoadPlaceTags([]*model.Place{place})) {
+			assert.Equal(t, []string{"airport"}, place.Tags)
+		}
+		tags, err = defaultDB.GetTags()
+		if assert.NoError(t, err) {
+			assert.Len(t, tags, 4)
+		}
+
+		// Deleting the tag of the tenant leaves the places of other tenants tagged
+		mco, err := defaultDB.GetPlaceByID(3)
+		if assert.NoError(t, acmeDB.DeleteTag(airport.ID)) && assert.NoError(t, err) &&
+			assert.NoError(t, defaultDB.LoadPlaceTags([]*model.Place{place, mco})) {
+			assert.Empty(t, place.Tags)
+			assert.Equal(t, []string{"airport", "fl"}, mco.Tags)
+		}
+	})
+}
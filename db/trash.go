@@ -0,0 +1,94 @@
+package db
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// ErrNotDeleted indicates a place cannot be restored as it has not been deleted.
+var ErrNotDeleted = errors.New("place is not deleted")
+
+// whereDeleted restricts the scope to places within the trash.
+func whereDeleted(scope *gorm.DB) *gorm.DB {
+	return scope.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+// whereNotDeleted excludes places within the trash from a scope which does not exclude them automatically,
+// such as a scope of the places table rather than the place model.
+func whereNotDeleted(scope *gorm.DB) *gorm.DB {
+	return scope.Where("places.deleted_at IS NULL")
+}
+
+// GetDeletedPlaces retrieves the places within the trash, most recently deleted first.
+func (db *Database) GetDeletedPlaces(limit int) (*PlacePage, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	scope := db.whereVisible(whereDeleted(db.DB)).Model(&model.Place{})
+	page := &PlacePage{}
+	if err := scope.Count(&page.Total).Error; err != nil {
+		return nil, wrapError(err, "unable to count deleted places")
+	}
+
+	err := scope.Order("deleted_at DESC").Order("id DESC").Limit(limit).Find(&page.Places).Error
+	return page, wrapError(err, "unable to find deleted places")
+}
+
+// RestorePlace removes the place from the trash, incrementing its version.
+// Restoring fails with ErrConflict when another place has since taken its name.
+func (db *Database) RestorePlace(id uint) (*model.Place, error) {
+	result := db.whereTenant(whereDeleted(db.DB)).Model(&model.Place{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": gorm.NowFunc(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, wrapError(result.Error, "unable to restore place")
+	}
+	if result.RowsAffected == 0 {
+		var place model.Place
+		if err := db.whereTenant(db.Unscoped()).Select("id").First(&place, id).Error; err != nil {
+			return nil, wrapError(err, "unable to restore place")
+		}
+		return nil, wrapError(ErrNotDeleted, "unable to restore place")
+	}
+
+	return db.GetPlaceByID(id)
+}
+
+// PurgePlaces permanently removes the places deleted before the given time, along with their tags and revisions,
+// returning the number of places removed.
+func (db *Database) PurgePlaces(before time.Time) (purged int64, err error) {
+	condition := "deleted_at < ?"
+	if db.Dialect().GetName() == "sqlite3" {
+		// SQLite stores timestamps as text in more than one format, so compare the instants instead
+		condition = "julianday(deleted_at) < julianday(?)"
+	}
+
+	err = db.Transaction(func(tx *Database) error {
+		expired := tx.whereTenant(whereDeleted(tx.DB)).Model(&model.Place{}).Where(condition, before).Select("id")
+		err := tx.Where("place_id IN (?)", expired.QueryExpr()).Delete(&model.PlaceTag{}).Error
+		if err != nil {
+			return wrapError(err, "unable to untag purged places")
+		}
+		err = tx.Where("place_id IN (?)", expired.QueryExpr()).Delete(&model.PlaceRevision{}).Error
+		if err != nil {
+			return wrapError(err, "unable to remove revisions of purged places")
+		}
+
+		result := tx.whereTenant(whereDeleted(tx.DB)).Where(condition, before).Delete(&model.Place{})
+		if result.Error != nil {
+			return wrapError(result.Error, "unable to purge places")
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	return purged, err
+}
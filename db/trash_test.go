@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func TestDatabase_Trash(t *testing.T) {
+	t.Parallel()
+	forEachDialect(t, func(t *testing.T, placeDB *Database) {
+		place, err := placeDB.GetPlaceByID(4)
+		if !assert.NoError(t, err) || !assert.NoError(t, placeDB.DeletePlace(place)) {
+			return
+		}
+
+		_, err = placeDB.GetPlaceByID(4)
+		assert.ErrorIs(t, err, ErrNotFound)
+		page, err := placeDB.GetPlaces(&PlaceQuery{})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 9, page.Total)
+		}
+		page, err = placeDB.SearchPlaces(&PlaceQuery{Text: "dam"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, page.Total)
+		}
+
+		trash, err := placeDB.GetDeletedPlaces(0)
+		if assert.NoError(t, err) && assert.Equal(t, 1, trash.Total) {
+			assert.Equal(t, "Hoover Dam", trash.Places[0].Name)
+			assert.NotNil(t, trash.Places[0].DeletedAt)
+		}
+
+		// The name of a deleted place may be reused, preventing the deleted place from being restored
+		reused := &model.Place{ID: 20, Name: "Hoover Dam"}
+		if assert.NoError(t, placeDB.CreatePlace(reused)) {
+			_, err = placeDB.RestorePlace(4)
+			assert.ErrorIs(t, err, ErrConflict)
+			assert.NoError(t, placeDB.DeletePlace(reused))
+		}
+
+		restored, err := placeDB.RestorePlace(4)
+		if assert.NoError(t, err) {
+			assert.Nil(t, restored.DeletedAt)
+			assert.Equal(t, place.Version+1, restored.Version)
+		}
+		_, err = placeDB.RestorePlace(4)
+		assert.ErrorIs(t, err, ErrNotDeleted)
+		_, err = placeDB.RestorePlace(99)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		purged, err := placeDB.PurgePlaces(time.Now().Add(-time.Hour))
+		if assert.NoError(t, err) {
+			assert.Zero(t, purged)
+		}
+		purged, err = placeDB.PurgePlaces(time.Now().Add(time.Hour))
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(1), purged)
+		}
+		trash, err = placeDB.GetDeletedPlaces(0)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, trash.Total)
+		}
+	})
+}
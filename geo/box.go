@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// BoundingBox is an area bounded by lines of latitude and longitude.
+// When MinLongitude is greater than MaxLongitude, the box crosses the antimeridian.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// ParseBoundingBox reads a box given as `minLon,minLat,maxLon,maxLat`.
+// A minimum longitude greater than the maximum describes a box crossing the antimeridian.
+func ParseBoundingBox(s string) (BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, fmt.Errorf("bounding box %q must be given as minLon,minLat,maxLon,maxLat", s)
+	}
+
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(value) {
+			return BoundingBox{}, fmt.Errorf("invalid coordinate %q in bounding box", part)
+		}
+		values[i] = value
+	}
+
+	box := BoundingBox{
+		MinLongitude: values[0],
+		MinLatitude:  values[1],
+		MaxLongitude: values[2],
+		MaxLatitude:  values[3],
+	}
+	return box, box.Validate()
+}
+
+// Validate ensures the corners of the box are valid coordinates and the latitudes are in order.
+func (b BoundingBox) Validate() error {
+	for _, corner := range []Point{
+		{Latitude: b.MinLatitude, Longitude: b.MinLongitude},
+		{Latitude: b.MaxLatitude, Longitude: b.MaxLongitude},
+	} {
+		if err := corner.Validate(); err != nil {
+			return err
+		}
+	}
+	if b.MinLatitude > b.MaxLatitude {
+		return fmt.Errorf("minimum latitude %v is greater than maximum latitude %v", b.MinLatitude, b.MaxLatitude)
+	}
+	return nil
+}
+
+// CrossesAntimeridian determines whether the box spans the 180th meridian.
+func (b BoundingBox) CrossesAntimeridian() bool {
+	return b.MinLongitude > b.MaxLongitude
+}
+
+// Contains determines whether the point lies within the box.
+func (b BoundingBox) Contains(p Point) bool {
+	if p.Latitude < b.MinLatitude || p.Latitude > b.MaxLatitude {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Longitude >= b.MinLongitude || p.Longitude <= b.MaxLongitude
+	}
+	return p.Longitude >= b.MinLongitude && p.Longitude <= b.MaxLongitude
+}
+
+// BoundingBoxAround calculates the smallest box containing every point within radius meters of the center.
+func BoundingBoxAround(center Point, radius float64) BoundingBox {
+	world := BoundingBox{MinLatitude: -90, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180}
+
+	angular := radius / EarthRadius
+	if angular >= math.Pi {
+		return world
+	}
+
+	lat := radians(center.Latitude)
+	minLat, maxLat := lat-angular, lat+angular
+	if minLat <= -math.Pi/2 || maxLat >= math.Pi/2 {
+		// The circle covers a pole, so every longitude is included
+		world.MinLatitude = math.Max(degrees(minLat), -90)
+		world.MaxLatitude = math.Min(degrees(maxLat), 90)
+		return world
+	}
+
+	deltaLon := degrees(math.Asin(math.Sin(angular) / math.Cos(lat)))
+	minLon, maxLon := center.Longitude-deltaLon, center.Longitude+deltaLon
+	if maxLon-minLon >= 360 {
+		minLon, maxLon = -180, 180
+	}
+	if minLon < -180 {
+		minLon += 360
+	}
+	if maxLon > 180 {
+		maxLon -= 360
+	}
+
+	return BoundingBox{
+		MinLatitude:  degrees(minLat),
+		MinLongitude: minLon,
+		MaxLatitude:  degrees(maxLat),
+		MaxLongitude: maxLon,
+	}
+}
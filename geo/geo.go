@@ -0,0 +1,83 @@
+// Package geo provides calculations for coordinates on the surface of the Earth.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// Point is a location given in decimal degrees.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// ParsePoint reads a point given as `lat,lon`.
+func ParsePoint(s string) (Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Point{}, fmt.Errorf("point %q must be given as lat,lon", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid latitude %q", parts[0])
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Point{}, fmt.Errorf("invalid longitude %q", parts[1])
+	}
+	p := Point{Latitude: lat, Longitude: lon}
+	return p, p.Validate()
+}
+
+// Validate ensures the point lies within the valid range of coordinates.
+func (p Point) Validate() error {
+	if math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90 {
+		return fmt.Errorf("latitude %v must be between -90 and 90", p.Latitude)
+	}
+	if math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180 {
+		return fmt.Errorf("longitude %v must be between -180 and 180", p.Longitude)
+	}
+	return nil
+}
+
+// Distance calculates the great-circle distance in meters between two points using the haversine formula.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ParseDistance reads a distance such as `500m`, `5km`, or `3mi` and returns the number of meters.
+// A distance without units is taken to be in meters.
+func ParseDistance(s string) (float64, error) {
+	units := map[string]float64{"km": 1000, "mi": 1609.344, "m": 1, "": 1}
+
+	value := strings.ToLower(strings.TrimSpace(s))
+	suffix := strings.TrimLeft(value, "0123456789.")
+	multiplier, ok := units[suffix]
+	if !ok {
+		return 0, fmt.Errorf("unsupported distance units in %q", s)
+	}
+	n, err := strconv.ParseFloat(strings.TrimSuffix(value, suffix), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("distance %q must be a positive number", s)
+	}
+	return n * multiplier, nil
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
@@ -0,0 +1,114 @@
+package geo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+	mco := Point{Latitude: 28.42461, Longitude: -81.31075}
+	mia := Point{Latitude: 25.79516, Longitude: -80.27959}
+
+	assert.InDelta(t, 309_000, Distance(mco, mia), 1_000)
+	assert.InDelta(t, Distance(mco, mia), Distance(mia, mco), 1e-6)
+	assert.Zero(t, Distance(mco, mco))
+	assert.InDelta(t, math.Pi*EarthRadius, Distance(Point{0, 0}, Point{0, 180}), 1e-6)
+}
+
+func TestParseDistance(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		input    string
+		expected float64
+		invalid  bool
+	}{
+		{input: "500", expected: 500},
+		{input: "500m", expected: 500},
+		{input: "5km", expected: 5000},
+		{input: "2.5KM", expected: 2500},
+		{input: "1mi", expected: 1609.344},
+		{input: "", invalid: true},
+		{input: "0km", invalid: true},
+		{input: "5 furlongs", invalid: true},
+		{input: "km", invalid: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+			actual, err := ParseDistance(tc.input)
+			if tc.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.InDelta(t, tc.expected, actual, 1e-9)
+			}
+		})
+	}
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		input        string
+		antimeridian bool
+		invalid      bool
+	}{
+		{input: "-87.6,24.5,-80,31"},
+		{input: "170,-20,-170,-10", antimeridian: true},
+		{input: "-87.6,24.5,-80", invalid: true},
+		{input: "-87.6,24.5,-80,31,5", invalid: true},
+		{input: "west,24.5,-80,31", invalid: true},
+		{input: "-87.6,31,-80,24.5", invalid: true},
+		{input: "-187.6,24.5,-80,31", invalid: true},
+		{input: "-87.6,24.5,-80,91", invalid: true},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+			box, err := ParseBoundingBox(tc.input)
+			if tc.invalid {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.antimeridian, box.CrossesAntimeridian())
+			}
+		})
+	}
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	t.Parallel()
+
+	t.Run("contains the circle", func(t *testing.T) {
+		t.Parallel()
+		center := Point{Latitude: 36.11274, Longitude: -115.17430}
+		box := BoundingBoxAround(center, 50_000)
+		assert.False(t, box.CrossesAntimeridian())
+		assert.True(t, box.Contains(Point{Latitude: 36.01604, Longitude: -114.73783}))
+		assert.False(t, box.Contains(Point{Latitude: 36.26603, Longitude: -112.36380}))
+	})
+
+	t.Run("crosses the antimeridian", func(t *testing.T) {
+		t.Parallel()
+		box := BoundingBoxAround(Point{Latitude: -17.7, Longitude: 179.9}, 100_000)
+		assert.True(t, box.CrossesAntimeridian())
+		assert.True(t, box.Contains(Point{Latitude: -17.7, Longitude: -179.8}))
+		assert.True(t, box.Contains(Point{Latitude: -17.7, Longitude: 179.5}))
+		assert.False(t, box.Contains(Point{Latitude: -17.7, Longitude: 0}))
+	})
+
+	t.Run("covers a pole", func(t *testing.T) {
+		t.Parallel()
+		box := BoundingBoxAround(Point{Latitude: 89.5, Longitude: 10}, 200_000)
+		assert.Equal(t, 90.0, box.MaxLatitude)
+		assert.Equal(t, -180.0, box.MinLongitude)
+		assert.Equal(t, 180.0, box.MaxLongitude)
+	})
+}
@@ -0,0 +1,176 @@
+package jwt
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Key verifies the signatures of tokens made with a single algorithm.
+type Key struct {
+	// ID matches the `kid` header of tokens signed by the key, or is empty to match any token
+	ID        string
+	Algorithm string
+	// Public holds the []byte secret of HS256 keys, the *rsa.PublicKey of RS256 keys, or the *ecdsa.PublicKey of
+	// ES256 keys
+	Public interface{}
+}
+
+// KeySet holds the keys trusted for verifying tokens.
+type KeySet struct {
+	Keys []*Key
+}
+
+// jsonWebKey is a key as represented within a JWKS document, per RFC 7517.
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// RSA public keys
+	N string `json:"n"`
+	E string `json:"e"`
+	// Elliptic curve public keys
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+	// Symmetric keys
+	K string `json:"k"`
+}
+
+// LoadKeySet reads a JWKS document from the file.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet reads a JWKS document holding RSA keys for RS256, P-256 keys for ES256, and symmetric keys for HS256.
+// Keys not intended for signatures, or of other types, are ignored.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var document struct {
+		Keys []*jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, jwk := range document.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.key()
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of JWKS: %w", i, err)
+		}
+		if jwk.Algorithm != "" && jwk.Algorithm != key.Algorithm {
+			continue
+		}
+		set.Keys = append(set.Keys, key)
+	}
+	return set, nil
+}
+
+// AddSecret trusts the secret for verifying HS256 tokens having the key ID, which may be empty.
+func (s *KeySet) AddSecret(id string, secret []byte) {
+	s.Keys = append(s.Keys, &Key{ID: id, Algorithm: HS256, Public: secret})
+}
+
+// find provides the keys which may have signed a token using the algorithm and key ID.
+func (s *KeySet) find(algorithm, id string) []*Key {
+	var keys []*Key
+	for _, key := range s.Keys {
+		if key.Algorithm == algorithm && (id == "" || key.ID == "" || key.ID == id) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// errUnsupportedKey is reported for keys which cannot be used by any supported algorithm.
+var errUnsupportedKey = errors.New("unsupported key")
+
+// key converts the JWK to a Key.
+func (jwk *jsonWebKey) key() (*Key, error) {
+	switch jwk.KeyType {
+	case "oct":
+		secret, err := decodeSegment(jwk.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid symmetric key")
+		}
+		return &Key{ID: jwk.KeyID, Algorithm: HS256, Public: secret}, nil
+	case "RSA":
+		public, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: jwk.KeyID, Algorithm: RS256, Public: public}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, errUnsupportedKey
+		}
+		public, err := jwk.ecdsaPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: jwk.KeyID, Algorithm: ES256, Public: public}, nil
+	default:
+		return nil, errUnsupportedKey
+	}
+}
+
+func (jwk *jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := decodeSegment(jwk.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid RSA modulus")
+	}
+	e, err := decodeSegment(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid RSA exponent")
+	}
+
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}
+	if public.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+	}
+	return public, nil
+}
+
+func (jwk *jsonWebKey) ecdsaPublicKey() (*ecdsa.PublicKey, error) {
+	x, err := decodeSegment(jwk.X)
+	if err != nil || len(x) != p256CoordinateSize {
+		return nil, errors.New("invalid EC x coordinate")
+	}
+	y, err := decodeSegment(jwk.Y)
+	if err != nil || len(y) != p256CoordinateSize {
+		return nil, errors.New("invalid EC y coordinate")
+	}
+
+	// Parsing the uncompressed point ensures it lies on the curve
+	point := append(append([]byte{4}, x...), y...)
+	if _, err = ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, fmt.Errorf("invalid EC point: %w", err)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,218 @@
+// Package jwt verifies JSON Web Tokens signed with HS256, RS256, or ES256.
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Signature algorithms supported for verifying tokens.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+const (
+	// minRSAKeyBits is the smallest RSA modulus trusted for verifying tokens
+	minRSAKeyBits = 2048
+	// p256CoordinateSize is the size of each coordinate of a P-256 point, and of each half of an ES256 signature
+	p256CoordinateSize = 32
+)
+
+// Errors reported when a token cannot be verified.
+var (
+	// ErrMalformed indicates the token is not a well-formed signed JWT
+	ErrMalformed = errors.New("token is malformed")
+	// ErrSignature indicates no trusted key verifies the signature of the token
+	ErrSignature = errors.New("token signature is invalid")
+	// ErrExpired indicates the time given by the `exp` claim has passed, or the claim is missing
+	ErrExpired = errors.New("token has expired")
+	// ErrNotYetValid indicates the time given by the `nbf` claim has not yet been reached
+	ErrNotYetValid = errors.New("token is not yet valid")
+	// ErrAudience indicates the `aud` claim does not name the expected audience
+	ErrAudience = errors.New("token is not intended for this audience")
+	// ErrIssuer indicates the `iss` claim does not name the expected issuer
+	ErrIssuer = errors.New("token was not issued by the expected issuer")
+)
+
+// Claims holds the claims of a verified token.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	// Raw holds every claim, including those above, keyed by name
+	Raw map[string]interface{}
+}
+
+// String provides the named claim when it holds a string.
+func (c *Claims) String(name string) string {
+	value, _ := c.Raw[name].(string)
+	return value
+}
+
+// Strings provides the named claim when it holds a string or an array of strings.
+func (c *Claims) Strings(name string) []string {
+	switch value := c.Raw[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Verifier checks the signatures and claims of tokens.
+type Verifier struct {
+	Keys *KeySet
+	// Issuer, when not empty, must be given by the `iss` claim of each token
+	Issuer string
+	// Audience, when not empty, must be named by the `aud` claim of each token
+	Audience string
+	// Leeway allows for differences between the clocks of the issuer and the verifier
+	Leeway time.Duration
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify checks the token was signed by a trusted key and is valid at the given time, providing its claims.
+// Tokens must have an `exp` claim.
+func (v *Verifier) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var h header
+	if err := decodeJSONSegment(parts[0], &h); err != nil {
+		return nil, ErrMalformed
+	}
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	// Only keys of the declared algorithm are tried, so public keys are never mistaken for HMAC secrets
+	signed := []byte(parts[0] + "." + parts[1])
+	if !slices.ContainsFunc(v.Keys.find(h.Algorithm, h.KeyID), func(key *Key) bool {
+		return key.verify(signed, signature)
+	}) {
+		return nil, ErrSignature
+	}
+
+	claims, err := parseClaims(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	if err = v.validate(claims, now); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate checks the registered claims of a token at the given time.
+func (v *Verifier) validate(claims *Claims, now time.Time) error {
+	switch {
+	case claims.ExpiresAt.IsZero() || !now.Before(claims.ExpiresAt.Add(v.Leeway)):
+		return ErrExpired
+	case !claims.NotBefore.IsZero() && now.Add(v.Leeway).Before(claims.NotBefore):
+		return ErrNotYetValid
+	case v.Issuer != "" && claims.Issuer != v.Issuer:
+		return ErrIssuer
+	case v.Audience != "" && !slices.Contains(claims.Audience, v.Audience):
+		return ErrAudience
+	}
+	return nil
+}
+
+// verify checks the signature of the signed content using the key.
+func (k *Key) verify(signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch public := k.Public.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, public)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature) != 2*p256CoordinateSize {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:p256CoordinateSize])
+		s := new(big.Int).SetBytes(signature[p256CoordinateSize:])
+		return ecdsa.Verify(public, digest[:], r, s)
+	default:
+		return false
+	}
+}
+
+// parseClaims reads the payload of a token.
+func parseClaims(payload string) (*Claims, error) {
+	claims := &Claims{Raw: map[string]interface{}{}}
+	if err := decodeJSONSegment(payload, &claims.Raw); err != nil {
+		return nil, ErrMalformed
+	}
+
+	claims.Subject = claims.String("sub")
+	claims.Issuer = claims.String("iss")
+	claims.Audience = claims.Strings("aud")
+	var err error
+	if claims.ExpiresAt, err = claims.timeClaim("exp"); err != nil {
+		return nil, err
+	}
+	if claims.NotBefore, err = claims.timeClaim("nbf"); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// timeClaim provides the named claim as a time given in seconds since the epoch, or the zero time when missing.
+func (c *Claims) timeClaim(name string) (time.Time, error) {
+	value, ok := c.Raw[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s must be a number", ErrMalformed, name)
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be a number", ErrMalformed, name)
+	}
+	return time.Unix(0, 0).Add(time.Duration(seconds * float64(time.Second))), nil
+}
+
+func decodeJSONSegment(s string, v interface{}) error {
+	data, err := decodeSegment(s)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,145 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign creates a token having the header and claims, signed using the private key.
+func sign(t *testing.T, h header, claims map[string]interface{}, private interface{}) string {
+	t.Helper()
+	encodedHeader, _ := json.Marshal(h)
+	encodedClaims, _ := json.Marshal(claims)
+	signed := encodeSegment(encodedHeader) + "." + encodeSegment(encodedClaims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch private := private.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, private)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, private, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + encodeSegment(signature)
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	t.Parallel()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","use":"sig","n":%q,"e":%q},
+		{"kty":"EC","kid":"ec","crv":"P-256","x":%q,"y":%q},
+		{"kty":"EC","kid":"p384","crv":"P-384","x":"AA","y":"AA"},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AA","e":"AQAB"}
+	]}`,
+		encodeSegment(rsaKey.N.Bytes()), encodeSegment(big.NewInt(int64(rsaKey.E)).Bytes()),
+		encodeSegment(ecKey.X.FillBytes(make([]byte, 32))), encodeSegment(ecKey.Y.FillBytes(make([]byte, 32))))
+	keys, err := ParseKeySet([]byte(jwks))
+	if !assert.NoError(t, err) || !assert.Len(t, keys.Keys, 2) {
+		return
+	}
+	keys.AddSecret("", secret)
+
+	now := time.Unix(1_700_000_000, 0)
+	verifier := &Verifier{Keys: keys, Issuer: "gateway", Audience: "weesvc", Leeway: time.Minute}
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub": "user-1",
+			"iss": "gateway",
+			"aud": []string{"other", "weesvc"},
+			"exp": now.Add(time.Hour).Unix(),
+			"nbf": now.Add(-time.Hour).Unix(),
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{"HS256", sign(t, header{Algorithm: HS256}, claims(nil), secret), nil},
+		{"RS256", sign(t, header{Algorithm: RS256, KeyID: "rsa"}, claims(nil), rsaKey), nil},
+		{"ES256", sign(t, header{Algorithm: ES256, KeyID: "ec"}, claims(nil), ecKey), nil},
+		{"string audience", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"aud": "weesvc"}), secret), nil},
+		{"within leeway", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"exp": now.Unix() - 30}), secret), nil},
+		{"wrong secret", sign(t, header{Algorithm: HS256}, claims(nil), []byte("wrong")), ErrSignature},
+		{"wrong key ID", sign(t, header{Algorithm: RS256, KeyID: "ec"}, claims(nil), rsaKey), ErrSignature},
+		{"unsupported algorithm", sign(t, header{Algorithm: "none"}, claims(nil), secret), ErrSignature},
+		{"expired", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"exp": now.Unix() - 120}), secret), ErrExpired},
+		{"no expiry", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"exp": nil}), secret), ErrExpired},
+		{"not yet valid", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"nbf": now.Unix() + 120}), secret), ErrNotYetValid},
+		{"wrong issuer", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"iss": "other"}), secret), ErrIssuer},
+		{"wrong audience", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"aud": "other"}), secret), ErrAudience},
+		{"malformed expiry", sign(t, header{Algorithm: HS256}, claims(map[string]interface{}{"exp": "soon"}), secret), ErrMalformed},
+		{"malformed", "not.a-token", ErrMalformed},
+	}
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			verified, err := verifier.Verify(tc.token, now)
+			if tc.expected != nil {
+				assert.ErrorIs(t, err, tc.expected)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, "user-1", verified.Subject)
+				assert.Contains(t, verified.Audience, "weesvc")
+			}
+		})
+	}
+}
+
+func TestParseKeySet_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ParseKeySet([]byte(`{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`))
+	assert.Error(t, err)
+	_, err = ParseKeySet([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"` + encodeSegment(make([]byte, 32)) +
+		`","y":"` + encodeSegment(make([]byte, 32)) + `"}]}`))
+	assert.Error(t, err)
+}
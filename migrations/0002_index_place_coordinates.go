@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var indexPlaceCoordinatesMigration0002 = &Migration{
+	Number: 2,
+	Name:   "Index place coordinates",
+	Forwards: func(db *gorm.DB) error {
+		const indexCoordinatesSQL = `
+			CREATE INDEX places_latitude_longitude_idx ON places(latitude, longitude);
+		`
+		err := db.Exec(indexCoordinatesSQL).Error
+		return errors.Wrap(err, "unable to index place coordinates")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, indexPlaceCoordinatesMigration0002)
+}
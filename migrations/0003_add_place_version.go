@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var addPlaceVersionMigration0003 = &Migration{
+	Number: 3,
+	Name:   "Add place version",
+	Forwards: func(db *gorm.DB) error {
+		const addVersionSQL = `
+			ALTER TABLE places ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+		`
+		err := db.Exec(addVersionSQL).Error
+		return errors.Wrap(err, "unable to add version to places table")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, addPlaceVersionMigration0003)
+}
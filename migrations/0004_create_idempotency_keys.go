@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createIdempotencyKeysMigration0004 = &Migration{
+	Number: 4,
+	Name:   "Create idempotency keys",
+	Forwards: func(db *gorm.DB) error {
+		const createIdempotencyKeysSQL = `
+			CREATE TABLE idempotency_keys(
+				idempotency_key TEXT PRIMARY KEY,
+				fingerprint TEXT NOT NULL,
+				status_code INTEGER NOT NULL DEFAULT 0,
+				header TEXT,
+				body TEXT,
+				created_at TIMESTAMP NOT NULL,
+				expires_at TIMESTAMP NOT NULL
+			);
+		`
+		if err := db.Exec(createIdempotencyKeysSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create idempotency_keys table")
+		}
+
+		const indexExpiresAtSQL = `
+			CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys(expires_at);
+		`
+		err := db.Exec(indexExpiresAtSQL).Error
+		return errors.Wrap(err, "unable to index idempotency_keys expiration")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createIdempotencyKeysMigration0004)
+}
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var addPlaceSearchMigration0005 = &Migration{
+	Number: 5,
+	Name:   "Add place search",
+	Forwards: func(db *gorm.DB) error {
+		if db.Dialect().GetName() == "postgres" {
+			return addPostgresPlaceSearch(db)
+		}
+		return addSQLitePlaceSearch(db)
+	},
+}
+
+// addPostgresPlaceSearch maintains a weighted text search vector of each place, indexed for matching.
+func addPostgresPlaceSearch(db *gorm.DB) error {
+	const addSearchSQL = `
+		ALTER TABLE places ADD COLUMN search tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B')
+		) STORED;
+	`
+	if err := db.Exec(addSearchSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to add search to places table")
+	}
+
+	const indexSearchSQL = `
+		CREATE INDEX places_search_idx ON places USING GIN(search);
+	`
+	err := db.Exec(indexSearchSQL).Error
+	return errors.Wrap(err, "unable to index places search")
+}
+
+// addSQLitePlaceSearch indexes places within an FTS5 table kept in sync by triggers.
+// SQLite builds lacking the FTS5 extension skip the index, leaving searches to match by pattern instead.
+func addSQLitePlaceSearch(db *gorm.DB) error {
+	const createSearchSQL = `
+		CREATE VIRTUAL TABLE places_fts USING fts5(name, description, content='places', content_rowid='id');
+	`
+	if err := db.Exec(createSearchSQL).Error; err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			logrus.Warn("SQLite lacks FTS5 support; place searches will match by pattern")
+			return nil
+		}
+		return errors.Wrap(err, "unable to create places_fts table")
+	}
+
+	const rebuildSearchSQL = `
+		INSERT INTO places_fts(places_fts) VALUES ('rebuild');
+	`
+	err := db.Exec(createSQLiteSearchTriggersSQL + rebuildSearchSQL).Error
+	return errors.Wrap(err, "unable to synchronize places_fts table")
+}
+
+// createSQLiteSearchTriggersSQL keeps the FTS5 index of places in sync as places change.
+const createSQLiteSearchTriggersSQL = `
+	CREATE TRIGGER places_fts_insert AFTER INSERT ON places BEGIN
+		INSERT INTO places_fts(rowid, name, description) VALUES (new.id, new.name, new.description);
+	END;
+	CREATE TRIGGER places_fts_delete AFTER DELETE ON places BEGIN
+		INSERT INTO places_fts(places_fts, rowid, name, description)
+		VALUES ('delete', old.id, old.name, old.description);
+	END;
+	CREATE TRIGGER places_fts_update AFTER UPDATE ON places BEGIN
+		INSERT INTO places_fts(places_fts, rowid, name, description)
+		VALUES ('delete', old.id, old.name, old.description);
+		INSERT INTO places_fts(rowid, name, description) VALUES (new.id, new.name, new.description);
+	END;
+`
+
+func init() {
+	Migrations = append(Migrations, addPlaceSearchMigration0005)
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var indexPlaceTrigramsMigration0006 = &Migration{
+	Number: 6,
+	Name:   "Index place trigrams",
+	Forwards: func(db *gorm.DB) error {
+		if db.Dialect().GetName() != "postgres" {
+			// SQLite lacks trigram support, so the application maintains its own index in memory
+			return nil
+		}
+
+		const createExtensionSQL = `
+			CREATE EXTENSION IF NOT EXISTS pg_trgm;
+		`
+		if err := db.Exec(createExtensionSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create pg_trgm extension")
+		}
+
+		const indexTrigramsSQL = `
+			CREATE INDEX places_name_trgm_idx ON places USING GIN(name gin_trgm_ops);
+			CREATE INDEX places_description_trgm_idx ON places USING GIN(description gin_trgm_ops);
+		`
+		err := db.Exec(indexTrigramsSQL).Error
+		return errors.Wrap(err, "unable to index place trigrams")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, indexPlaceTrigramsMigration0006)
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createTagsMigration0007 = &Migration{
+	Number: 7,
+	Name:   "Create tags",
+	Forwards: func(db *gorm.DB) error {
+		// Identifiers are generated by the database when a tag is created
+		idType := "INTEGER PRIMARY KEY"
+		if db.Dialect().GetName() == "postgres" {
+			idType = "SERIAL PRIMARY KEY"
+		}
+		createTagsSQL := `
+			CREATE TABLE tags(
+				id ` + idType + `,
+				name TEXT UNIQUE NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			);
+		`
+		if err := db.Exec(createTagsSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create tags table")
+		}
+
+		const createPlaceTagsSQL = `
+			CREATE TABLE place_tags(
+				place_id INTEGER NOT NULL REFERENCES places(id) ON DELETE CASCADE,
+				tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+				PRIMARY KEY(place_id, tag_id)
+			);
+		`
+		if err := db.Exec(createPlaceTagsSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create place_tags table")
+		}
+
+		const indexTagIDSQL = `
+			CREATE INDEX place_tags_tag_id_idx ON place_tags(tag_id);
+		`
+		err := db.Exec(indexTagIDSQL).Error
+		return errors.Wrap(err, "unable to index place_tags by tag")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createTagsMigration0007)
+}
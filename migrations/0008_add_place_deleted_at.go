@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var addPlaceDeletedAtMigration0008 = &Migration{
+	Number: 8,
+	Name:   "Add place deleted_at",
+	Forwards: func(db *gorm.DB) error {
+		if db.Dialect().GetName() == "postgres" {
+			return addPostgresPlaceDeletedAt(db)
+		}
+		return addSQLitePlaceDeletedAt(db)
+	},
+}
+
+// uniqueActivePlaceNameSQL allows the names of deleted places to be reused, while remaining unique
+// among the places which have not been deleted.
+const uniqueActivePlaceNameSQL = `
+	CREATE UNIQUE INDEX places_name_idx ON places(name) WHERE deleted_at IS NULL;
+	CREATE INDEX places_deleted_at_idx ON places(deleted_at);
+`
+
+func addPostgresPlaceDeletedAt(db *gorm.DB) error {
+	const addDeletedAtSQL = `
+		ALTER TABLE places ADD COLUMN deleted_at TIMESTAMP;
+		ALTER TABLE places DROP CONSTRAINT places_name_key;
+	`
+	if err := db.Exec(addDeletedAtSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to add deleted_at to places table")
+	}
+
+	err := db.Exec(uniqueActivePlaceNameSQL).Error
+	return errors.Wrap(err, "unable to index place names")
+}
+
+// addSQLitePlaceDeletedAt rebuilds the places table, as SQLite cannot drop the unique constraint of a column.
+// Indexes and triggers of the former table are recreated for the new table.
+func addSQLitePlaceDeletedAt(db *gorm.DB) error {
+	const rebuildPlacesSQL = `
+		CREATE TABLE places_new(
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			description TEXT,
+			latitude REAL,
+			longitude REAL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			version INTEGER NOT NULL DEFAULT 1,
+			deleted_at TIMESTAMP
+		);
+		INSERT INTO places_new(id, name, description, latitude, longitude, created_at, updated_at, version)
+		SELECT id, name, description, latitude, longitude, created_at, updated_at, version FROM places;
+		DROP TABLE places;
+		ALTER TABLE places_new RENAME TO places;
+		CREATE INDEX places_latitude_longitude_idx ON places(latitude, longitude);
+	`
+	if err := db.Exec(rebuildPlacesSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to rebuild places table")
+	}
+	if err := db.Exec(uniqueActivePlaceNameSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to index place names")
+	}
+
+	var searchTables int
+	err := db.Table("sqlite_master").Where("type = 'table' AND name = 'places_fts'").Count(&searchTables).Error
+	if err != nil {
+		return errors.Wrap(err, "unable to find places_fts table")
+	}
+	if searchTables == 0 {
+		return nil
+	}
+	err = db.Exec(createSQLiteSearchTriggersSQL).Error
+	return errors.Wrap(err, "unable to synchronize places_fts table")
+}
+
+func init() {
+	Migrations = append(Migrations, addPlaceDeletedAtMigration0008)
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createPlaceRevisionsMigration0009 = &Migration{
+	Number: 9,
+	Name:   "Create place revisions",
+	Forwards: func(db *gorm.DB) error {
+		// Identifiers are generated by the database when a revision is recorded
+		idType := "INTEGER PRIMARY KEY"
+		if db.Dialect().GetName() == "postgres" {
+			idType = "SERIAL PRIMARY KEY"
+		}
+		createPlaceRevisionsSQL := `
+			CREATE TABLE place_revisions(
+				id ` + idType + `,
+				place_id INTEGER NOT NULL,
+				revision INTEGER NOT NULL,
+				action TEXT NOT NULL,
+				snapshot TEXT NOT NULL,
+				changes TEXT NOT NULL,
+				actor TEXT,
+				trace_id TEXT,
+				created_at TIMESTAMP NOT NULL,
+				UNIQUE(place_id, revision)
+			);
+		`
+		err := db.Exec(createPlaceRevisionsSQL).Error
+		return errors.Wrap(err, "unable to create place_revisions table")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createPlaceRevisionsMigration0009)
+}
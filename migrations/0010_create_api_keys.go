@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createAPIKeysMigration0010 = &Migration{
+	Number: 10,
+	Name:   "Create API keys",
+	Forwards: func(db *gorm.DB) error {
+		// Identifiers are generated by the database when a key is created
+		idType := "INTEGER PRIMARY KEY"
+		if db.Dialect().GetName() == "postgres" {
+			idType = "SERIAL PRIMARY KEY"
+		}
+		createAPIKeysSQL := `
+			CREATE TABLE api_keys(
+				id ` + idType + `,
+				name TEXT NOT NULL,
+				prefix TEXT NOT NULL,
+				hash TEXT UNIQUE NOT NULL,
+				scopes TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				revoked_at TIMESTAMP
+			);
+		`
+		err := db.Exec(createAPIKeysSQL).Error
+		return errors.Wrap(err, "unable to create api_keys table")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createAPIKeysMigration0010)
+}
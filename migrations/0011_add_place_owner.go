@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var addPlaceOwnerMigration0011 = &Migration{
+	Number: 11,
+	Name:   "Add place owner",
+	Forwards: func(db *gorm.DB) error {
+		// Places created before ownership was recorded have no owner, leaving them shared
+		const addOwnerSQL = `
+			ALTER TABLE places ADD COLUMN owner_id TEXT;
+		`
+		if err := db.Exec(addOwnerSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to add owner to places table")
+		}
+
+		const indexOwnerSQL = `
+			CREATE INDEX places_owner_id_idx ON places(owner_id);
+		`
+		err := db.Exec(indexOwnerSQL).Error
+		return errors.Wrap(err, "unable to index place owners")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, addPlaceOwnerMigration0011)
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createTenantsMigration0012 = &Migration{
+	Number: 12,
+	Name:   "Create tenants",
+	Forwards: func(db *gorm.DB) error {
+		// Existing places and API keys belong to the default tenant
+		const createTenantsSQL = `
+			CREATE TABLE tenants(
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL
+			);
+			INSERT INTO tenants(id, name, created_at) VALUES ('default', 'Default', CURRENT_TIMESTAMP);
+		`
+		if err := db.Exec(createTenantsSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create tenants table")
+		}
+
+		const addTenantSQL = `
+			ALTER TABLE places ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+			ALTER TABLE api_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+		`
+		if err := db.Exec(addTenantSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to add tenant to places and api_keys tables")
+		}
+
+		// Names remain unique among the places of each tenant which have not been deleted
+		const indexTenantNameSQL = `
+			DROP INDEX places_name_idx;
+			CREATE UNIQUE INDEX places_tenant_id_name_idx ON places(tenant_id, name) WHERE deleted_at IS NULL;
+		`
+		err := db.Exec(indexTenantNameSQL).Error
+		return errors.Wrap(err, "unable to index place names by tenant")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createTenantsMigration0012)
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var createQuotaCountersMigration0013 = &Migration{
+	Number: 13,
+	Name:   "Create quota counters",
+	Forwards: func(db *gorm.DB) error {
+		const createQuotaCountersSQL = `
+			CREATE TABLE quota_counters(
+				client TEXT NOT NULL,
+				route_group TEXT NOT NULL,
+				day TEXT NOT NULL,
+				requests INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY(client, route_group, day)
+			);
+		`
+		if err := db.Exec(createQuotaCountersSQL).Error; err != nil {
+			return errors.Wrap(err, "unable to create quota_counters table")
+		}
+
+		// Counters of past days are removed by day
+		const indexDaySQL = `
+			CREATE INDEX quota_counters_day_idx ON quota_counters(day);
+		`
+		err := db.Exec(indexDaySQL).Error
+		return errors.Wrap(err, "unable to index quota_counters by day")
+	},
+}
+
+func init() {
+	Migrations = append(Migrations, createQuotaCountersMigration0013)
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+var addTagTenantMigration0014 = &Migration{
+	Number: 14,
+	Name:   "Add tag tenant",
+	Forwards: func(db *gorm.DB) error {
+		var err error
+		if db.Dialect().GetName() == "postgres" {
+			err = addPostgresTagTenant(db)
+		} else {
+			err = addSQLiteTagTenant(db)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Existing tags belong to the default tenant, so the places of other tenants are given tags of their own
+		const copyTenantTagsSQL = `
+			INSERT INTO tags(tenant_id, name, created_at, updated_at)
+			SELECT DISTINCT places.tenant_id, tags.name, tags.created_at, tags.updated_at
+			FROM place_tags
+			JOIN places ON places.id = place_tags.place_id
+			JOIN tags ON tags.id = place_tags.tag_id
+			WHERE places.tenant_id <> 'default';
+			UPDATE place_tags SET tag_id = (
+				SELECT tenant_tags.id
+				FROM places
+				JOIN tags ON tags.id = place_tags.tag_id
+				JOIN tags tenant_tags ON tenant_tags.tenant_id = places.tenant_id AND tenant_tags.name = tags.name
+				WHERE places.id = place_tags.place_id
+			)
+			WHERE place_id IN (SELECT id FROM places WHERE tenant_id <> 'default');
+		`
+		err = db.Exec(copyTenantTagsSQL).Error
+		return errors.Wrap(err, "unable to copy tags into tenants")
+	},
+}
+
+// uniqueTenantTagNameSQL keeps names unique among the tags of each tenant.
+const uniqueTenantTagNameSQL = `
+	CREATE UNIQUE INDEX tags_tenant_id_name_idx ON tags(tenant_id, name);
+`
+
+func addPostgresTagTenant(db *gorm.DB) error {
+	const addTenantSQL = `
+		ALTER TABLE tags ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default';
+		ALTER TABLE tags DROP CONSTRAINT tags_name_key;
+	`
+	if err := db.Exec(addTenantSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to add tenant to tags table")
+	}
+
+	err := db.Exec(uniqueTenantTagNameSQL).Error
+	return errors.Wrap(err, "unable to index tag names by tenant")
+}
+
+// addSQLiteTagTenant rebuilds the tags table, as SQLite cannot drop the unique constraint of a column.
+func addSQLiteTagTenant(db *gorm.DB) error {
+	const rebuildTagsSQL = `
+		CREATE TABLE tags_new(
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			tenant_id TEXT NOT NULL DEFAULT 'default'
+		);
+		INSERT INTO tags_new(id, name, created_at, updated_at)
+		SELECT id, name, created_at, updated_at FROM tags;
+		DROP TABLE tags;
+		ALTER TABLE tags_new RENAME TO tags;
+	`
+	if err := db.Exec(rebuildTagsSQL).Error; err != nil {
+		return errors.Wrap(err, "unable to rebuild tags table")
+	}
+
+	err := db.Exec(uniqueTenantTagNameSQL).Error
+	return errors.Wrap(err, "unable to index tag names by tenant")
+}
+
+func init() {
+	Migrations = append(Migrations, addTagTenantMigration0014)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes granting access to the API.
+const (
+	// ScopePlacesRead permits reading places and tags
+	ScopePlacesRead = "places:read"
+	// ScopePlacesWrite permits changing places
+	ScopePlacesWrite = "places:write"
+	// ScopeAdmin permits every request, including changes to the tags shared by all places
+	ScopeAdmin = "admin"
+)
+
+// APIKey grants clients access to the API. Only a hash of each key is stored, so the key itself is revealed once
+// when created.
+type APIKey struct {
+	ID   uint `gorm:"primary_key"`
+	Name string
+	// Prefix holds the leading characters of the key, for recognizing keys without revealing them
+	Prefix string
+	// Hash is the hex-encoded SHA-256 digest of the key
+	Hash string
+	// Scopes holds the scopes granted to the key, separated by spaces
+	Scopes string
+	// TenantID identifies the tenant whose places the key grants access to
+	TenantID  string
+	CreatedAt time.Time
+	// RevokedAt is when the key was revoked, after which it is no longer accepted
+	RevokedAt *time.Time
+}
+
+// ScopeList provides each scope granted to the key.
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// GeoJSON object types utilized for places.
+const (
+	GeoJSONFeature           = "Feature"
+	GeoJSONFeatureCollection = "FeatureCollection"
+	GeoJSONPoint             = "Point"
+)
+
+// Geometry is a GeoJSON geometry object; only points are supported for places.
+type Geometry struct {
+	Type string `json:"type"`
+	// Coordinates of a point are given as longitude then latitude
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// FeatureProperties are the attributes of a place other than its location.
+type FeatureProperties struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Distance    *float64   `json:"distance_m,omitempty"`
+}
+
+// Feature is the GeoJSON representation of a place.
+type Feature struct {
+	Type       string            `json:"type"`
+	ID         uint              `json:"id,omitempty"`
+	Geometry   *Geometry         `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+// FeatureCollection is the GeoJSON representation of a list of places.
+type FeatureCollection struct {
+	Type     string     `json:"type"`
+	Features []*Feature `json:"features"`
+}
+
+// NewFeature creates the GeoJSON representation of the place.
+func NewFeature(place *Place) *Feature {
+	feature := &Feature{
+		Type: GeoJSONFeature,
+		ID:   place.ID,
+		Geometry: &Geometry{
+			Type:        GeoJSONPoint,
+			Coordinates: []float64{place.Longitude, place.Latitude},
+		},
+		Properties: FeatureProperties{
+			Name:        place.Name,
+			Description: place.Description,
+			Tags:        place.Tags,
+			Distance:    place.Distance,
+		},
+	}
+	if !place.CreatedAt.IsZero() {
+		feature.Properties.CreatedAt = &place.CreatedAt
+	}
+	if !place.UpdatedAt.IsZero() {
+		feature.Properties.UpdatedAt = &place.UpdatedAt
+	}
+	return feature
+}
+
+// NewFeatureCollection creates the GeoJSON representation of the places.
+func NewFeatureCollection(places []*Place) *FeatureCollection {
+	collection := &FeatureCollection{
+		Type:     GeoJSONFeatureCollection,
+		Features: make([]*Feature, 0, len(places)),
+	}
+	for _, place := range places {
+		collection.Features = append(collection.Features, NewFeature(place))
+	}
+	return collection
+}
+
+// Place converts the feature into a new place, ensuring it is located by a point geometry.
+func (f *Feature) Place() (*Place, error) {
+	if f.Type != GeoJSONFeature {
+		return nil, fmt.Errorf("expected GeoJSON type %q but found %q", GeoJSONFeature, f.Type)
+	}
+	if f.Geometry == nil || f.Geometry.Type != GeoJSONPoint {
+		return nil, fmt.Errorf("feature geometry must be a %q", GeoJSONPoint)
+	}
+	if len(f.Geometry.Coordinates) < 2 {
+		return nil, fmt.Errorf("point coordinates must provide longitude and latitude")
+	}
+
+	return &Place{
+		Name:        f.Properties.Name,
+		Description: f.Properties.Description,
+		Tags:        f.Properties.Tags,
+		Latitude:    f.Geometry.Coordinates[1],
+		Longitude:   f.Geometry.Coordinates[0],
+	}, nil
+}
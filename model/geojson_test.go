@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFeature(t *testing.T) {
+	t.Parallel()
+	place := &Place{ID: 6, Name: "MIA", Description: "Miami International Airport, FL, USA", Latitude: 25.79516, Longitude: -80.27959}
+
+	data, err := json.Marshal(NewFeature(place))
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{
+			"type": "Feature",
+			"id": 6,
+			"geometry": {"type": "Point", "coordinates": [-80.27959, 25.79516]},
+			"properties": {"name": "MIA", "description": "Miami International Airport, FL, USA"}
+		}`, string(data))
+	}
+}
+
+func TestFeature_Place(t *testing.T) {
+	t.Parallel()
+
+	var feature Feature
+	err := json.Unmarshal([]byte(`{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-20.88530, 64.04126]},
+		"properties": {"name": "Kerid Crater", "description": "Kerid Crater, Iceland"}
+	}`), &feature)
+	if assert.NoError(t, err) {
+		place, err := feature.Place()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Kerid Crater", place.Name)
+			assert.Equal(t, "Kerid Crater, Iceland", place.Description)
+			assert.Equal(t, 64.04126, place.Latitude)
+			assert.Equal(t, -20.88530, place.Longitude)
+		}
+	}
+
+	_, err = (&Feature{Type: GeoJSONFeature}).Place()
+	assert.Error(t, err)
+	_, err = (&Feature{Type: GeoJSONFeatureCollection, Geometry: &Geometry{Type: GeoJSONPoint}}).Place()
+	assert.Error(t, err)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// IdempotencyKey records the outcome of a request made with an `Idempotency-Key` so that retries of the
+// request receive the original response instead of repeating its effects.
+type IdempotencyKey struct {
+	Key string `gorm:"column:idempotency_key;primary_key"`
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string
+	// StatusCode of the recorded response, or zero while the original request is still being processed
+	StatusCode int
+	// Header holds the recorded response headers encoded as JSON
+	Header    string
+	Body      string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the response to the original request has been recorded.
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
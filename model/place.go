@@ -12,4 +12,21 @@ type Place struct {
 	Longitude   float64   `json:"longitude"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// DeletedAt is set when the place is moved to the trash, excluding it from all other reads
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// OwnerID identifies the principal owning the place, or is nil when the place is shared
+	OwnerID *string `json:"owner_id,omitempty"`
+	// TenantID identifies the tenant the place belongs to, which alone may see it
+	TenantID string `json:"-"`
+	// Version is incremented with each update to detect concurrent modifications
+	Version uint `json:"-"`
+	// Tags classifying the place, ordered by name. Changes leave the tags of a place untouched when nil
+	Tags []string `gorm:"-" json:"tags"`
+
+	// Distance in meters from the point of a proximity search
+	Distance *float64 `gorm:"-" json:"distance_m,omitempty"`
+	// Snippet of the text matching a search, with the matched terms highlighted
+	Snippet *string `gorm:"-" json:"snippet,omitempty"`
+	// Similarity of the place to the text of a fuzzy search, from 0 to 1
+	Similarity *float64 `gorm:"-" json:"similarity,omitempty"`
 }
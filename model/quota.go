@@ -0,0 +1,12 @@
+package model
+
+// QuotaCounter counts the requests made by a client to a group of routes during a single day, in UTC, so that
+// daily quotas survive restarts and are shared by every instance of the service.
+type QuotaCounter struct {
+	// Client identifies the principal or address making the requests, such as `apikey:3`
+	Client     string `gorm:"primary_key"`
+	RouteGroup string `gorm:"primary_key"`
+	// Day is the date of the requests, formatted as `2006-01-02`
+	Day      string `gorm:"primary_key"`
+	Requests int
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Actions recorded by the revisions of a place.
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+	RevisionRevert  = "revert"
+)
+
+// PlaceRevision records a change made to a place, along with the full state of the place following the change.
+type PlaceRevision struct {
+	ID      uint `gorm:"primary_key"`
+	PlaceID uint
+	// Revision numbers the changes made to each place, starting from 1
+	Revision uint
+	Action   string
+	// Snapshot holds the PlaceSnapshot following the change encoded as JSON
+	Snapshot string
+	// Changes holds the FieldChange of each changed attribute, keyed by attribute, encoded as JSON
+	Changes string
+	// Actor identifies who made the change; empty for changes made by commands
+	Actor     string
+	TraceID   string
+	CreatedAt time.Time
+}
+
+// PlaceSnapshot is the state of the attributes of a place which may be changed.
+type PlaceSnapshot struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Latitude    float64  `json:"latitude"`
+	Longitude   float64  `json:"longitude"`
+	Tags        []string `json:"tags"`
+}
+
+// FieldChange describes the values of an attribute before and after a change.
+type FieldChange struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to"`
+}
+
+// NewPlaceSnapshot captures the current state of the place.
+func NewPlaceSnapshot(place *Place) *PlaceSnapshot {
+	tags := place.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &PlaceSnapshot{
+		Name:        place.Name,
+		Description: place.Description,
+		Latitude:    place.Latitude,
+		Longitude:   place.Longitude,
+		Tags:        tags,
+	}
+}
+
+// Apply changes the place to the state of the snapshot.
+func (s *PlaceSnapshot) Apply(place *Place) {
+	place.Name = s.Name
+	place.Description = s.Description
+	place.Latitude = s.Latitude
+	place.Longitude = s.Longitude
+	place.Tags = append([]string{}, s.Tags...)
+}
+
+// Diff describes each attribute having a different value within the other snapshot. Every attribute of the
+// other snapshot is described when the snapshot is nil, such as when a place is created.
+func (s *PlaceSnapshot) Diff(other *PlaceSnapshot) map[string]*FieldChange {
+	changes := map[string]*FieldChange{}
+	record := func(field string, from, to interface{}, changed bool) {
+		switch {
+		case s == nil:
+			changes[field] = &FieldChange{To: to}
+		case changed:
+			changes[field] = &FieldChange{From: from, To: to}
+		}
+	}
+
+	before := s
+	if before == nil {
+		before = &PlaceSnapshot{}
+	}
+	record("name", before.Name, other.Name, before.Name != other.Name)
+	record("description", before.Description, other.Description, before.Description != other.Description)
+	record("latitude", before.Latitude, other.Latitude, before.Latitude != other.Latitude)
+	record("longitude", before.Longitude, other.Longitude, before.Longitude != other.Longitude)
+	record("tags", before.Tags, other.Tags, !slices.Equal(before.Tags, other.Tags))
+	return changes
+}
+
+// DecodeSnapshot provides the state of the place following the change.
+func (r *PlaceRevision) DecodeSnapshot() (*PlaceSnapshot, error) {
+	var snapshot PlaceSnapshot
+	if err := json.Unmarshal([]byte(r.Snapshot), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// DecodeChanges provides the change of each attribute, keyed by attribute.
+func (r *PlaceRevision) DecodeChanges() (map[string]*FieldChange, error) {
+	changes := map[string]*FieldChange{}
+	if err := json.Unmarshal([]byte(r.Changes), &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// DecodePreviousSnapshot provides the state of the place preceding the change, by reverting each changed attribute
+// of the snapshot. Places have no state preceding revisions of RevisionCreate.
+func (r *PlaceRevision) DecodePreviousSnapshot() (*PlaceSnapshot, error) {
+	if r.Action == RevisionCreate {
+		return nil, fmt.Errorf("place %d did not exist before revision %d", r.PlaceID, r.Revision)
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(r.Snapshot), &attributes); err != nil {
+		return nil, err
+	}
+	changes, err := r.DecodeChanges()
+	if err != nil {
+		return nil, err
+	}
+	for field, change := range changes {
+		attributes[field] = change.From
+	}
+
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot PlaceSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlaceSnapshot_Diff(t *testing.T) {
+	t.Parallel()
+	before := &PlaceSnapshot{Name: "Hoover Dam", Description: "NV", Latitude: 36, Longitude: -114, Tags: []string{"nv"}}
+	after := &PlaceSnapshot{Name: "Hoover Dam", Description: "NV, USA", Latitude: 36, Longitude: -114, Tags: []string{}}
+
+	assert.Equal(t, map[string]*FieldChange{
+		"description": {From: "NV", To: "NV, USA"},
+		"tags":        {From: []string{"nv"}, To: []string{}},
+	}, before.Diff(after))
+	assert.Empty(t, after.Diff(after))
+
+	var created *PlaceSnapshot
+	changes := created.Diff(after)
+	assert.Len(t, changes, 5)
+	assert.Equal(t, &FieldChange{To: "Hoover Dam"}, changes["name"])
+}
+
+func TestPlaceRevision_DecodePreviousSnapshot(t *testing.T) {
+	t.Parallel()
+	before := &PlaceSnapshot{Name: "Hoover Dam", Description: "NV", Latitude: 36, Longitude: -114, Tags: []string{"nv"}}
+	after := &PlaceSnapshot{Name: "Hoover Dam", Description: "NV, USA", Latitude: 36.01, Longitude: -114, Tags: []string{}}
+	snapshot, _ := json.Marshal(after)
+	changes, _ := json.Marshal(before.Diff(after))
+
+	revision := &PlaceRevision{Action: RevisionUpdate, Snapshot: string(snapshot), Changes: string(changes)}
+	previous, err := revision.DecodePreviousSnapshot()
+	if assert.NoError(t, err) {
+		assert.Equal(t, before, previous)
+	}
+
+	revision.Action = RevisionCreate
+	_, err = revision.DecodePreviousSnapshot()
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Tag classifies places, such as airports or parks.
+type Tag struct {
+	ID   uint   `gorm:"primary_key" json:"id"`
+	Name string `json:"name"`
+	// TenantID identifies the tenant the tag belongs to, whose places alone it may classify
+	TenantID  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PlaceTag associates a tag with a place.
+type PlaceTag struct {
+	PlaceID uint `gorm:"primary_key;auto_increment:false"`
+	TagID   uint `gorm:"primary_key;auto_increment:false"`
+}
+
+// TagCount is the number of places having a tag.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// DefaultTenant identifies the tenant of places and API keys created without naming a tenant.
+const DefaultTenant = "default"
+
+// Tenant is a team sharing the deployment, whose places are kept apart from those of every other tenant.
+type Tenant struct {
+	// ID names the tenant within the `X-Tenant` header, such as `maps-team`
+	ID        string    `gorm:"primary_key" json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,13 +1,3 @@
-CREATE TABLE IF NOT EXISTS places(
-    id INTEGER PRIMARY KEY,
-    name TEXT UNIQUE NOT NULL,
-    description TEXT,
-    latitude REAL,
-    longitude REAL,
-    created_at TIMESTAMP NOT NULL,
-    updated_at TIMESTAMP NOT NULL
-);
-
 INSERT INTO places (id, name, description, latitude, longitude, created_at, updated_at)
 VALUES
     (1, 'Mount Rushmore', 'Mount Rushmore National Memorial, SD, USA', 43.88031, -103.45387, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
@@ -20,3 +10,20 @@ VALUES
     (8, 'Grand Canyon', 'Grand Canyon National Park, AZ, USA', 36.26603, -112.36380, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
     (9, 'Hollywood Studios', 'Disney''s Hollywood Studios, FL, USA', 28.35801, -81.55918, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
     (10, 'ORD', 'O''Hare International Airport, Chicago, IL, USA', 41.97861, -87.90472, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+INSERT INTO tags (name, created_at, updated_at)
+VALUES
+    ('airport', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('fl', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('nv', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+    ('park', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+INSERT INTO place_tags (place_id, tag_id)
+SELECT 3, id FROM tags WHERE name IN ('airport', 'fl')
+UNION ALL SELECT 6, id FROM tags WHERE name IN ('airport', 'fl')
+UNION ALL SELECT 10, id FROM tags WHERE name = 'airport'
+UNION ALL SELECT 9, id FROM tags WHERE name = 'fl'
+UNION ALL SELECT 2, id FROM tags WHERE name = 'nv'
+UNION ALL SELECT 4, id FROM tags WHERE name = 'nv'
+UNION ALL SELECT 1, id FROM tags WHERE name = 'park'
+UNION ALL SELECT 8, id FROM tags WHERE name = 'park';
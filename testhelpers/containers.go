@@ -3,7 +3,6 @@ package testhelpers
 
 import (
 	"context"
-	"path/filepath"
 	"time"
 
 	"github.com/testcontainers/testcontainers-go"
@@ -20,10 +19,10 @@ type PostgresContainer struct {
 }
 
 // CreatePostgresContainer creates a new container instance associated to the provided context.
+// The database within is empty, left for `PrepareDatabase` to migrate and seed.
 func CreatePostgresContainer(ctx context.Context) (*PostgresContainer, error) {
 	pgContainer, err := postgres.RunContainer(ctx,
 		testcontainers.WithImage("postgres:15.3-alpine"),
-		postgres.WithInitScripts(filepath.Join("..", "testdata", "init-db.sql")),
 		postgres.WithDatabase("test-db"),
 		postgres.WithUsername("postgres"),
 		postgres.WithPassword("postgres"),
@@ -0,0 +1,34 @@
+package testhelpers
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/migrations"
+)
+
+// PrepareDatabase readies an empty database by applying each migration in order, then seeds it with the places
+// of `testdata/seed-db.sql`.
+func PrepareDatabase(db *gorm.DB) error {
+	// The shared list of migrations is sorted within a copy, as tests prepare databases in parallel
+	scripts := make([]*migrations.Migration, len(migrations.Migrations))
+	copy(scripts, migrations.Migrations)
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Number < scripts[j].Number
+	})
+	for _, migration := range scripts {
+		if err := migration.Forwards(db); err != nil {
+			return errors.Wrapf(err, "unable to apply migration %d", migration.Number)
+		}
+	}
+
+	seed, err := os.ReadFile(filepath.Join("..", "testdata", "seed-db.sql"))
+	if err != nil {
+		return errors.Wrap(err, "unable to read seed data")
+	}
+	return errors.Wrap(db.Exec(string(seed)).Error, "unable to seed database")
+}
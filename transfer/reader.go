@@ -0,0 +1,234 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Row is a single place read from a source, identified by its position within the source.
+type Row struct {
+	// Number is the line of CSV and JSON lines sources, or the index of a GeoJSON feature, starting at 1
+	Number int
+	Place  *model.Place
+	// Err describes why the row could not be read; the remaining rows may still be read
+	Err error
+}
+
+// Reader provides places from a source one row at a time.
+type Reader interface {
+	// Read returns the next row, or io.EOF when no rows remain.
+	// Any other error indicates the source cannot be read further.
+	Read() (*Row, error)
+}
+
+// NewReader creates a reader of places in the given format.
+func NewReader(format Format, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return &jsonlReader{scanner: bufio.NewScanner(r)}, nil
+	case FormatGeoJSON:
+		return newGeoJSONReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// placeRecord is the representation of a place within CSV and JSON lines sources. Coordinates are held as pointers
+// so that a missing coordinate is told apart from zero. Tags are nil when not given, leaving those of an existing
+// place untouched.
+type placeRecord struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	Tags        []string `json:"tags"`
+}
+
+// place converts the record into a new place, ensuring both coordinates were given.
+func (p *placeRecord) place() (*model.Place, error) {
+	switch {
+	case p.Latitude == nil:
+		return nil, errors.New("latitude is required")
+	case p.Longitude == nil:
+		return nil, errors.New("longitude is required")
+	}
+	return &model.Place{
+		Name:        p.Name,
+		Description: p.Description,
+		Latitude:    *p.Latitude,
+		Longitude:   *p.Longitude,
+		Tags:        p.Tags,
+	}, nil
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVReader reads the header row to locate the columns, which may be given in any order.
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read CSV header")
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{"name", "latitude", "longitude"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", column)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (*Row, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return &Row{Number: perr.Line, Err: perr.Err}, nil
+		}
+		return nil, err
+	}
+
+	line, _ := c.reader.FieldPos(0)
+	row := &Row{Number: line}
+
+	var input placeRecord
+	for _, column := range []string{"name", "description", "latitude", "longitude", "tags"} {
+		i, ok := c.columns[column]
+		if !ok || i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case "name":
+			input.Name = value
+		case "description":
+			input.Description = value
+		case "tags":
+			input.Tags = splitTags(value)
+		case "latitude", "longitude":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				row.Err = fmt.Errorf("%s %q is not a number", column, value)
+				return row, nil
+			}
+			if column == "latitude" {
+				input.Latitude = &n
+			} else {
+				input.Longitude = &n
+			}
+		}
+	}
+
+	row.Place, row.Err = input.place()
+	return row, nil
+}
+
+// splitTags separates the tags held within a CSV field, ignoring any left empty.
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, tagSeparator) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (j *jsonlReader) Read() (*Row, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := bytes.TrimSpace(j.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		row := &Row{Number: j.line}
+		var input placeRecord
+		if err := json.Unmarshal(data, &input); err != nil {
+			row.Err = err
+		} else {
+			row.Place, row.Err = input.place()
+		}
+		return row, nil
+	}
+
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type geoJSONReader struct {
+	features []json.RawMessage
+	next     int
+}
+
+// newGeoJSONReader reads the document holding either a FeatureCollection or a single Feature.
+func newGeoJSONReader(r io.Reader) (*geoJSONReader, error) {
+	var document struct {
+		Type     string            `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, errors.Wrap(err, "unable to read GeoJSON")
+	}
+
+	switch document.Type {
+	case model.GeoJSONFeatureCollection:
+		return &geoJSONReader{features: document.Features}, nil
+	case model.GeoJSONFeature:
+		return &geoJSONReader{features: []json.RawMessage{data}}, nil
+	default:
+		return nil, fmt.Errorf("expected a GeoJSON %s or %s but found %q",
+			model.GeoJSONFeatureCollection, model.GeoJSONFeature, document.Type)
+	}
+}
+
+func (g *geoJSONReader) Read() (*Row, error) {
+	if g.next >= len(g.features) {
+		return nil, io.EOF
+	}
+	g.next++
+
+	row := &Row{Number: g.next}
+	var feature model.Feature
+	if err := json.Unmarshal(g.features[g.next-1], &feature); err != nil {
+		row.Err = err
+	} else {
+		row.Place, row.Err = feature.Place()
+	}
+	return row, nil
+}
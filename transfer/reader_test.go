@@ -0,0 +1,104 @@
+package transfer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, reader Reader) []*Row {
+	t.Helper()
+	var rows []*Row
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if !assert.NoError(t, err) {
+			return rows
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	t.Parallel()
+	reader, err := NewReader(FormatCSV, strings.NewReader(
+		"Latitude,Longitude,Name,Description,Tags\n"+
+			"25.79516,-80.27959,MIA,\"Miami International Airport, FL, USA\",airport; fl;\n"+
+			"north,-80.27959,Bad,\n"+
+			"25.79516\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rows := readAll(t, reader)
+	if assert.Len(t, rows, 3) {
+		assert.Equal(t, 2, rows[0].Number)
+		assert.NoError(t, rows[0].Err)
+		assert.Equal(t, "MIA", rows[0].Place.Name)
+		assert.Equal(t, "Miami International Airport, FL, USA", rows[0].Place.Description)
+		assert.Equal(t, 25.79516, rows[0].Place.Latitude)
+		assert.Equal(t, -80.27959, rows[0].Place.Longitude)
+		assert.Equal(t, []string{"airport", "fl"}, rows[0].Place.Tags)
+
+		assert.Equal(t, 3, rows[1].Number)
+		assert.Error(t, rows[1].Err)
+		assert.EqualError(t, rows[2].Err, "longitude is required")
+	}
+
+	_, err = NewReader(FormatCSV, strings.NewReader("name,description\n"))
+	assert.Error(t, err)
+}
+
+func TestJSONLReader(t *testing.T) {
+	t.Parallel()
+	reader, err := NewReader(FormatJSONL, strings.NewReader(
+		`{"name":"MIA","latitude":25.79516,"longitude":-80.27959,"tags":["airport"]}`+"\n\n{bad}\n"+
+			`{"name":"Null Island","latitude":0,"longitude":0}`+"\n"+
+			`{"name":"Nowhere","latitude":25.79516}`+"\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rows := readAll(t, reader)
+	if assert.Len(t, rows, 4) {
+		assert.Equal(t, 1, rows[0].Number)
+		assert.Equal(t, "MIA", rows[0].Place.Name)
+		assert.Equal(t, []string{"airport"}, rows[0].Place.Tags)
+		assert.Equal(t, 3, rows[1].Number)
+		assert.Error(t, rows[1].Err)
+		assert.NoError(t, rows[2].Err)
+		assert.Equal(t, "Null Island", rows[2].Place.Name)
+		assert.Nil(t, rows[2].Place.Tags)
+		assert.EqualError(t, rows[3].Err, "longitude is required")
+	}
+}
+
+func TestGeoJSONReader(t *testing.T) {
+	t.Parallel()
+	reader, err := NewReader(FormatGeoJSON, strings.NewReader(`{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-80.27959, 25.79516]}, "properties": {"name": "MIA"}},
+			{"type": "Feature", "geometry": null, "properties": {"name": "Nowhere"}}
+		]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rows := readAll(t, reader)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, "MIA", rows[0].Place.Name)
+		assert.Equal(t, 25.79516, rows[0].Place.Latitude)
+		assert.Equal(t, 2, rows[1].Number)
+		assert.Error(t, rows[1].Err)
+	}
+
+	_, err = NewReader(FormatGeoJSON, strings.NewReader(`{"type": "Point", "coordinates": [0, 0]}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// Package transfer provides for reading and writing places in common interchange formats.
+package transfer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies an interchange format for places.
+type Format string
+
+// Supported interchange formats.
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatGeoJSON Format = "geojson"
+	FormatKML     Format = "kml"
+	FormatGPX     Format = "gpx"
+)
+
+// tagSeparator divides the tags of a place held within a single field, as tag names cannot contain it.
+const tagSeparator = ";"
+
+// ParseFormat validates the name of an interchange format.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(s)); format {
+	case FormatCSV, FormatJSONL, FormatGeoJSON, FormatKML, FormatGPX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q", s)
+	}
+}
+
+// FormatForPath determines the interchange format from the extension of the file path.
+func FormatForPath(path string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".geojson", ".json":
+		return FormatGeoJSON, nil
+	case ".kml":
+		return FormatKML, nil
+	case ".gpx":
+		return FormatGPX, nil
+	default:
+		return "", fmt.Errorf("unable to determine format of %q; specify the format explicitly", path)
+	}
+}
+
+// ContentType provides the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatJSONL:
+		return "application/jsonl"
+	case FormatGeoJSON:
+		return "application/geo+json"
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	case FormatGPX:
+		return "application/gpx+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
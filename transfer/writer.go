@@ -0,0 +1,264 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+// Writer encodes places to a destination one at a time.
+type Writer interface {
+	// Write encodes the place.
+	Write(place *model.Place) error
+	// Close completes the document and flushes any buffered output; it does not close the destination.
+	Close() error
+}
+
+// NewWriter creates a writer of places in the given format.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	buffered := bufio.NewWriter(w)
+	switch format {
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(buffered), buffered: buffered}, nil
+	case FormatJSONL:
+		return &jsonlWriter{encoder: json.NewEncoder(buffered), buffered: buffered}, nil
+	case FormatGeoJSON:
+		return &geoJSONWriter{buffered: buffered}, nil
+	case FormatKML:
+		return newXMLWriter(buffered, kmlHeader, kmlFooter, kmlPlacemark), nil
+	case FormatGPX:
+		return newXMLWriter(buffered, gpxHeader, gpxFooter, gpxWaypoint), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+type csvWriter struct {
+	writer   *csv.Writer
+	buffered *bufio.Writer
+	started  bool
+}
+
+// start writes the header row before the first place, or at close when there are no places.
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.writer.Write([]string{
+		"id", "name", "description", "latitude", "longitude", "tags", "created_at", "updated_at",
+	})
+}
+
+func (c *csvWriter) Write(place *model.Place) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.writer.Write([]string{
+		strconv.FormatUint(uint64(place.ID), 10),
+		place.Name,
+		place.Description,
+		formatCoordinate(place.Latitude),
+		formatCoordinate(place.Longitude),
+		strings.Join(place.Tags, tagSeparator),
+		place.CreatedAt.Format(time.RFC3339Nano),
+		place.UpdatedAt.Format(time.RFC3339Nano),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+	return c.buffered.Flush()
+}
+
+type jsonlWriter struct {
+	encoder  *json.Encoder
+	buffered *bufio.Writer
+}
+
+func (j *jsonlWriter) Write(place *model.Place) error {
+	return j.encoder.Encode(place)
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.buffered.Flush()
+}
+
+// geoJSONWriter streams a FeatureCollection one feature at a time.
+type geoJSONWriter struct {
+	buffered *bufio.Writer
+	count    int
+}
+
+func (g *geoJSONWriter) Write(place *model.Place) error {
+	prefix := ","
+	if g.count == 0 {
+		prefix = `{"type":"FeatureCollection","features":[`
+	}
+	g.count++
+
+	data, err := json.Marshal(model.NewFeature(place))
+	if err != nil {
+		return err
+	}
+	if _, err := g.buffered.WriteString(prefix + "\n"); err != nil {
+		return err
+	}
+	_, err = g.buffered.Write(data)
+	return err
+}
+
+func (g *geoJSONWriter) Close() error {
+	suffix := "\n]}\n"
+	if g.count == 0 {
+		suffix = `{"type":"FeatureCollection","features":[]}` + "\n"
+	}
+	if _, err := g.buffered.WriteString(suffix); err != nil {
+		return err
+	}
+	return g.buffered.Flush()
+}
+
+const (
+	kmlHeader = xml.Header + `<kml xmlns="http://www.opengis.net/kml/2.2"><Document>` + "\n"
+	kmlFooter = "</Document></kml>\n"
+	gpxHeader = xml.Header + `<gpx version="1.1" creator="weesvc" xmlns="http://www.topografix.com/GPX/1/1">` + "\n"
+	gpxFooter = "</gpx>\n"
+)
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// kmlData is a named value of the extended data of a placemark.
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlExtendedData struct {
+	Data []kmlData `xml:"Data"`
+}
+
+type kmlPlacemarkElement struct {
+	XMLName      xml.Name         `xml:"Placemark"`
+	ID           string           `xml:"id,attr"`
+	Name         string           `xml:"name"`
+	Description  string           `xml:"description,omitempty"`
+	ExtendedData *kmlExtendedData `xml:"ExtendedData,omitempty"`
+	Point        kmlPoint         `xml:"Point"`
+}
+
+func kmlPlacemark(place *model.Place) interface{} {
+	placemark := &kmlPlacemarkElement{
+		ID:          "place-" + strconv.FormatUint(uint64(place.ID), 10),
+		Name:        place.Name,
+		Description: place.Description,
+		Point:       kmlPoint{Coordinates: formatCoordinate(place.Longitude) + "," + formatCoordinate(place.Latitude)},
+	}
+	if len(place.Tags) > 0 {
+		placemark.ExtendedData = &kmlExtendedData{
+			Data: []kmlData{{Name: "tags", Value: strings.Join(place.Tags, tagSeparator)}},
+		}
+	}
+	return placemark
+}
+
+// gpxExtensions holds the tags of a waypoint, which GPX lacks an element for, within the namespace of this service.
+type gpxExtensions struct {
+	Tags string `xml:"https://github.com/weesvc/weesvc-gorilla tags"`
+}
+
+type gpxWaypointElement struct {
+	XMLName     xml.Name       `xml:"wpt"`
+	Latitude    string         `xml:"lat,attr"`
+	Longitude   string         `xml:"lon,attr"`
+	Time        string         `xml:"time,omitempty"`
+	Name        string         `xml:"name"`
+	Description string         `xml:"desc,omitempty"`
+	Extensions  *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+func gpxWaypoint(place *model.Place) interface{} {
+	waypoint := &gpxWaypointElement{
+		Latitude:    formatCoordinate(place.Latitude),
+		Longitude:   formatCoordinate(place.Longitude),
+		Name:        place.Name,
+		Description: place.Description,
+	}
+	if len(place.Tags) > 0 {
+		waypoint.Extensions = &gpxExtensions{Tags: strings.Join(place.Tags, tagSeparator)}
+	}
+	if !place.UpdatedAt.IsZero() {
+		waypoint.Time = place.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return waypoint
+}
+
+// xmlWriter streams elements created for each place between a fixed header and footer.
+type xmlWriter struct {
+	buffered *bufio.Writer
+	encoder  *xml.Encoder
+	header   string
+	footer   string
+	element  func(place *model.Place) interface{}
+	started  bool
+}
+
+func newXMLWriter(buffered *bufio.Writer, header, footer string, element func(*model.Place) interface{}) *xmlWriter {
+	return &xmlWriter{
+		buffered: buffered,
+		encoder:  xml.NewEncoder(buffered),
+		header:   header,
+		footer:   footer,
+		element:  element,
+	}
+}
+
+func (x *xmlWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+	_, err := x.buffered.WriteString(x.header)
+	return err
+}
+
+func (x *xmlWriter) Write(place *model.Place) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if err := x.encoder.Encode(x.element(place)); err != nil {
+		return err
+	}
+	_, err := x.buffered.WriteString("\n")
+	return err
+}
+
+func (x *xmlWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if _, err := x.buffered.WriteString(x.footer); err != nil {
+		return err
+	}
+	return x.buffered.Flush()
+}
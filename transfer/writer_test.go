@@ -0,0 +1,99 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weesvc/weesvc-gorilla/model"
+)
+
+func testPlaces() []*model.Place {
+	return []*model.Place{
+		{
+			ID: 6, Name: "MIA", Description: "Miami International Airport, FL, USA", Latitude: 25.79516, Longitude: -80.27959,
+			Tags: []string{"airport", "fl"},
+		},
+		{ID: 10, Name: "ORD", Description: "O'Hare International Airport, Chicago, IL, USA", Latitude: 41.97861, Longitude: -87.90472},
+	}
+}
+
+func writeAll(t *testing.T, format Format, places []*model.Place) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	for _, place := range places {
+		assert.NoError(t, writer.Write(place))
+	}
+	assert.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, format := range []Format{FormatCSV, FormatJSONL, FormatGeoJSON} {
+		format := format // pin
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+			data := writeAll(t, format, testPlaces())
+
+			reader, err := NewReader(format, bytes.NewReader(data))
+			if !assert.NoError(t, err) {
+				return
+			}
+			rows := readAll(t, reader)
+			if assert.Len(t, rows, 2) {
+				for i, expected := range testPlaces() {
+					assert.NoError(t, rows[i].Err)
+					assert.Equal(t, expected.Name, rows[i].Place.Name)
+					assert.Equal(t, expected.Description, rows[i].Place.Description)
+					assert.Equal(t, expected.Latitude, rows[i].Place.Latitude)
+					assert.Equal(t, expected.Longitude, rows[i].Place.Longitude)
+					assert.ElementsMatch(t, expected.Tags, rows[i].Place.Tags)
+				}
+			}
+		})
+	}
+}
+
+func TestWriter_Empty(t *testing.T) {
+	t.Parallel()
+
+	var collection model.FeatureCollection
+	if assert.NoError(t, json.Unmarshal(writeAll(t, FormatGeoJSON, nil), &collection)) {
+		assert.Equal(t, model.GeoJSONFeatureCollection, collection.Type)
+		assert.Empty(t, collection.Features)
+	}
+
+	assert.Equal(t, "id,name,description,latitude,longitude,tags,created_at,updated_at\n", string(writeAll(t, FormatCSV, nil)))
+}
+
+func TestWriter_XML(t *testing.T) {
+	t.Parallel()
+	for _, format := range []Format{FormatKML, FormatGPX} {
+		format := format // pin
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+			data := writeAll(t, format, testPlaces())
+
+			decoder := xml.NewDecoder(bytes.NewReader(data))
+			for {
+				_, err := decoder.Token()
+				if err != nil {
+					assert.ErrorIs(t, err, io.EOF)
+					break
+				}
+			}
+			assert.True(t, strings.Contains(string(data), "O&#39;Hare"))
+			assert.True(t, strings.Contains(string(data), "airport;fl"))
+		})
+	}
+}